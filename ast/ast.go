@@ -2,6 +2,8 @@ package ast
 
 import (
 	"bytes"
+	"snek/token"
+	"strconv"
 	"strings"
 )
 
@@ -95,9 +97,19 @@ func (n *NumberNode) Write(w *ASTWriter) {
 	w.WriteString(n.Value)
 }
 
+type StringNode struct {
+	Value string
+}
+
+func (n *StringNode) String() string { return strconv.Quote(n.Value) }
+
+func (n *StringNode) Write(w *ASTWriter) {
+	w.WriteString(strconv.Quote(n.Value))
+}
+
 type AssignmentNode struct {
-	Target   Node
-	Operator string
+	Targets  []Node
+	Operator token.TokenType
 	Value    Node
 }
 
@@ -109,14 +121,16 @@ func (n *AssignmentNode) String() string {
 
 func (n *AssignmentNode) Write(w *ASTWriter) {
 	w.writeIndent()
-	n.Target.Write(w)
-	w.WriteString(" " + n.Operator + " ")
+	for _, target := range n.Targets {
+		target.Write(w)
+		w.WriteString(" " + n.Operator.Symbol() + " ")
+	}
 	n.Value.Write(w)
 	w.WriteString("\n")
 }
 
 type PrefixNode struct {
-	Operator string
+	Operator token.TokenType
 	Right    Node
 }
 
@@ -127,14 +141,14 @@ func (n *PrefixNode) String() string {
 }
 
 func (n *PrefixNode) Write(w *ASTWriter) {
-	w.WriteString("(" + n.Operator + " ")
+	w.WriteString("(" + n.Operator.Symbol() + " ")
 	n.Right.Write(w)
 	w.WriteString(")")
 }
 
 type InfixNode struct {
 	Left     Node
-	Operator string
+	Operator token.TokenType
 	Right    Node
 }
 
@@ -147,7 +161,7 @@ func (n *InfixNode) String() string {
 func (n *InfixNode) Write(w *ASTWriter) {
 	w.WriteString("(")
 	n.Left.Write(w)
-	w.WriteString(" " + n.Operator + " ")
+	w.WriteString(" " + n.Operator.Symbol() + " ")
 	n.Right.Write(w)
 	w.WriteString(")")
 }
@@ -216,6 +230,65 @@ func (n *ControlNode) Write(w *ASTWriter) {
 	w.WriteLine(n.Type)
 }
 
+// AliasNode is one name in an import statement, with its optional 'as' name.
+type AliasNode struct {
+	Name   string
+	AsName string
+}
+
+func (n *AliasNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *AliasNode) Write(w *ASTWriter) {
+	w.WriteString(n.Name)
+	if n.AsName != "" {
+		w.WriteString(" as " + n.AsName)
+	}
+}
+
+func joinAliases(names []*AliasNode) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+type ImportNode struct {
+	Names []*AliasNode
+}
+
+func (n *ImportNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ImportNode) Write(w *ASTWriter) {
+	w.WriteLine("import " + joinAliases(n.Names))
+}
+
+// ImportFromNode is 'from module import names'. Level counts the leading dots
+// of a relative import; a single "*" name imports everything public.
+type ImportFromNode struct {
+	Module string
+	Level  int
+	Names  []*AliasNode
+}
+
+func (n *ImportFromNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ImportFromNode) Write(w *ASTWriter) {
+	w.WriteLine("from " + strings.Repeat(".", n.Level) + n.Module + " import " + joinAliases(n.Names))
+}
+
 type ReturnNode struct {
 	Value Node
 }
@@ -227,9 +300,154 @@ func (n *ReturnNode) String() string {
 }
 
 func (n *ReturnNode) Write(w *ASTWriter) {
+	if n.Value == nil {
+		w.WriteLine("return")
+		return
+	}
 	w.WriteLine("return " + safeString(n.Value))
 }
 
+type AssertNode struct {
+	Test    Node
+	Message Node // nil without a message
+}
+
+func (n *AssertNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *AssertNode) Write(w *ASTWriter) {
+	if n.Message != nil {
+		w.WriteLine("assert " + safeString(n.Test) + ", " + safeString(n.Message))
+		return
+	}
+	w.WriteLine("assert " + safeString(n.Test))
+}
+
+type DelNode struct {
+	Targets []Node
+}
+
+func (n *DelNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *DelNode) Write(w *ASTWriter) {
+	w.WriteLine("del " + joinNodes(n.Targets, ", "))
+}
+
+// RaiseNode is 'raise exc from cause'. A bare raise has a nil Exception and
+// re-raises the exception being handled.
+type RaiseNode struct {
+	Exception Node
+	Cause     Node
+}
+
+func (n *RaiseNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *RaiseNode) Write(w *ASTWriter) {
+	switch {
+	case n.Exception == nil:
+		w.WriteLine("raise")
+	case n.Cause != nil:
+		w.WriteLine("raise " + safeString(n.Exception) + " from " + safeString(n.Cause))
+	default:
+		w.WriteLine("raise " + safeString(n.Exception))
+	}
+}
+
+type TryNode struct {
+	Body     Node
+	Handlers []*ExceptHandlerNode
+	Else     Node
+	Finally  Node
+}
+
+func (n *TryNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *TryNode) Write(w *ASTWriter) {
+	w.WriteLine("try:")
+	w.Indent()
+	n.Body.Write(w)
+	w.Dedent()
+	for _, h := range n.Handlers {
+		h.Write(w)
+	}
+	if n.Else != nil {
+		w.WriteLine("else:")
+		w.Indent()
+		n.Else.Write(w)
+		w.Dedent()
+	}
+	if n.Finally != nil {
+		w.WriteLine("finally:")
+		w.Indent()
+		n.Finally.Write(w)
+		w.Dedent()
+	}
+}
+
+// ExceptHandlerNode is one except clause. Type is nil for a bare except.
+type ExceptHandlerNode struct {
+	Type Node
+	Name Node // nil without 'as'
+	Body Node
+}
+
+func (n *ExceptHandlerNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ExceptHandlerNode) Write(w *ASTWriter) {
+	switch {
+	case n.Type == nil:
+		w.WriteLine("except:")
+	case n.Name != nil:
+		w.WriteLine("except " + safeString(n.Type) + " as " + safeString(n.Name) + ":")
+	default:
+		w.WriteLine("except " + safeString(n.Type) + ":")
+	}
+	w.Indent()
+	n.Body.Write(w)
+	w.Dedent()
+}
+
+type YieldNode struct {
+	Value Node
+	From  bool
+}
+
+func (n *YieldNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *YieldNode) Write(w *ASTWriter) {
+	switch {
+	case n.From:
+		w.WriteString("yield from " + safeString(n.Value))
+	case n.Value != nil:
+		w.WriteString("yield " + safeString(n.Value))
+	default:
+		w.WriteString("yield")
+	}
+}
+
 type ForNode struct {
 	Targets Node
 	Values  Node
@@ -256,10 +474,53 @@ func (n *ForNode) Write(w *ASTWriter) {
 	}
 }
 
+type WithNode struct {
+	Items []*WithItemNode
+	Body  Node
+}
+
+func (n *WithNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *WithNode) Write(w *ASTWriter) {
+	items := make([]string, len(n.Items))
+	for i, item := range n.Items {
+		items[i] = item.String()
+	}
+
+	w.WriteLine("with " + strings.Join(items, ", ") + ":")
+	w.Indent()
+	n.Body.Write(w)
+	w.Dedent()
+}
+
+type WithItemNode struct {
+	Context Node
+	Target  Node // nil without 'as'
+}
+
+func (n *WithItemNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *WithItemNode) Write(w *ASTWriter) {
+	w.WriteString(safeString(n.Context))
+	if n.Target != nil {
+		w.WriteString(" as " + safeString(n.Target))
+	}
+}
+
 type FunctionDefNode struct {
-	Name   Node
-	Params []Node
-	Body   Node
+	Name        Node
+	Params      []Node
+	Body        Node
+	Decorators  []Node
+	IsGenerator bool // Set when the body contains yield
 }
 
 func (n *FunctionDefNode) String() string {
@@ -269,6 +530,7 @@ func (n *FunctionDefNode) String() string {
 }
 
 func (n *FunctionDefNode) Write(w *ASTWriter) {
+	writeDecorators(w, n.Decorators)
 	w.WriteString("def " + safeString(n.Name) + "(")
 
 	for i, param := range n.Params {
@@ -284,9 +546,106 @@ func (n *FunctionDefNode) Write(w *ASTWriter) {
 	w.Dedent()
 }
 
+func writeDecorators(w *ASTWriter, decorators []Node) {
+	for _, d := range decorators {
+		w.WriteLine("@" + safeString(d))
+	}
+}
+
+type ClassDefNode struct {
+	Name       Node
+	Bases      []Node
+	Body       Node
+	Decorators []Node
+}
+
+func (n *ClassDefNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ClassDefNode) Write(w *ASTWriter) {
+	writeDecorators(w, n.Decorators)
+	w.WriteString("class " + safeString(n.Name))
+
+	if len(n.Bases) > 0 {
+		w.WriteString("(")
+		for i, base := range n.Bases {
+			base.Write(w)
+			if i < len(n.Bases)-1 {
+				w.WriteString(", ")
+			}
+		}
+		w.WriteString(")")
+	}
+
+	w.WriteLine(":")
+	w.Indent()
+	n.Body.Write(w)
+	w.Dedent()
+}
+
+type LambdaNode struct {
+	Params      []Node
+	Body        Node
+	IsGenerator bool
+}
+
+func (n *LambdaNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *LambdaNode) Write(w *ASTWriter) {
+	w.WriteString("lambda")
+	for i, param := range n.Params {
+		if i == 0 {
+			w.WriteString(" ")
+		} else {
+			w.WriteString(", ")
+		}
+		param.Write(w)
+	}
+	w.WriteString(": " + safeString(n.Body))
+}
+
+type NamedExprNode struct {
+	Target Node
+	Value  Node
+}
+
+func (n *NamedExprNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *NamedExprNode) Write(w *ASTWriter) {
+	w.WriteString("(" + safeString(n.Target) + " := " + safeString(n.Value) + ")")
+}
+
+type ConditionalNode struct {
+	Condition Node
+	Body      Node
+	Else      Node
+}
+
+func (n *ConditionalNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ConditionalNode) Write(w *ASTWriter) {
+	w.WriteString(safeString(n.Body) + " if " + safeString(n.Condition) + " else " + safeString(n.Else))
+}
+
 type ParamNode struct {
 	Name         Node
 	DefaultValue Node
+	Prefix       string // "*" or "**" for variadic parameters; a bare "*" has no Name
 }
 
 func (n *ParamNode) String() string {
@@ -296,7 +655,11 @@ func (n *ParamNode) String() string {
 }
 
 func (n *ParamNode) Write(w *ASTWriter) {
-	w.WriteString(safeString(n.Name))
+	if n.Prefix != "" && n.Name == nil {
+		w.WriteString(n.Prefix)
+		return
+	}
+	w.WriteString(n.Prefix + safeString(n.Name))
 	if n.DefaultValue != nil {
 		w.WriteString("=" + safeString(n.DefaultValue))
 	}
@@ -343,6 +706,33 @@ func (n *SliceNode) Write(w *ASTWriter) {
 	w.WriteString("]")
 }
 
+// SliceExprNode is the 'lower:upper:step' index of a subscript. Any part may be nil.
+type SliceExprNode struct {
+	Lower Node
+	Upper Node
+	Step  Node
+}
+
+func (n *SliceExprNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *SliceExprNode) Write(w *ASTWriter) {
+	if n.Lower != nil {
+		n.Lower.Write(w)
+	}
+	w.WriteString(":")
+	if n.Upper != nil {
+		n.Upper.Write(w)
+	}
+	if n.Step != nil {
+		w.WriteString(":")
+		n.Step.Write(w)
+	}
+}
+
 type ExpressionsNode struct {
 	Expressions []Node
 }
@@ -361,3 +751,434 @@ func (n *ExpressionsNode) Write(w *ASTWriter) {
 		}
 	}
 }
+
+type TupleNode struct {
+	Elements []Node
+}
+
+func (n *TupleNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *TupleNode) Write(w *ASTWriter) {
+	w.WriteString("(")
+	for i, elem := range n.Elements {
+		elem.Write(w)
+		if i < len(n.Elements)-1 || len(n.Elements) == 1 {
+			w.WriteString(",")
+		}
+		if i < len(n.Elements)-1 {
+			w.WriteString(" ")
+		}
+	}
+	w.WriteString(")")
+}
+
+type ListNode struct {
+	Elements []Node
+}
+
+func (n *ListNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ListNode) Write(w *ASTWriter) {
+	w.WriteString("[")
+	for i, elem := range n.Elements {
+		elem.Write(w)
+		if i < len(n.Elements)-1 {
+			w.WriteString(", ")
+		}
+	}
+	w.WriteString("]")
+}
+
+type StarredNode struct {
+	Value Node
+}
+
+func (n *StarredNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *StarredNode) Write(w *ASTWriter) {
+	w.WriteString("*")
+	n.Value.Write(w)
+}
+
+type DoubleStarredNode struct {
+	Value Node
+}
+
+func (n *DoubleStarredNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *DoubleStarredNode) Write(w *ASTWriter) {
+	w.WriteString("**")
+	n.Value.Write(w)
+}
+
+type DictNode struct {
+	Keys   []Node
+	Values []Node
+}
+
+func (n *DictNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *DictNode) Write(w *ASTWriter) {
+	w.WriteString("{")
+	for i := range n.Keys {
+		n.Keys[i].Write(w)
+		w.WriteString(": ")
+		n.Values[i].Write(w)
+		if i < len(n.Keys)-1 {
+			w.WriteString(", ")
+		}
+	}
+	w.WriteString("}")
+}
+
+type KeywordNode struct {
+	Name  Node
+	Value Node
+}
+
+func (n *KeywordNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *KeywordNode) Write(w *ASTWriter) {
+	w.WriteString(safeString(n.Name) + "=")
+	n.Value.Write(w)
+}
+
+type SetNode struct {
+	Elements []Node
+}
+
+func (n *SetNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *SetNode) Write(w *ASTWriter) {
+	w.WriteString("{")
+	for i, elem := range n.Elements {
+		elem.Write(w)
+		if i < len(n.Elements)-1 {
+			w.WriteString(", ")
+		}
+	}
+	w.WriteString("}")
+}
+
+// ComprehensionNode is one 'for ... in ...' clause of a comprehension, along
+// with the 'if' conditions that follow it.
+type ComprehensionNode struct {
+	Targets Node
+	Values  Node
+	Ifs     []Node
+}
+
+func (n *ComprehensionNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ComprehensionNode) Write(w *ASTWriter) {
+	w.WriteString(" for " + safeString(n.Targets) + " in " + safeString(n.Values))
+	for _, cond := range n.Ifs {
+		w.WriteString(" if " + safeString(cond))
+	}
+}
+
+func writeClauses(w *ASTWriter, clauses []*ComprehensionNode) {
+	for _, clause := range clauses {
+		clause.Write(w)
+	}
+}
+
+type ListCompNode struct {
+	Element Node
+	Clauses []*ComprehensionNode
+}
+
+func (n *ListCompNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *ListCompNode) Write(w *ASTWriter) {
+	w.WriteString("[" + safeString(n.Element))
+	writeClauses(w, n.Clauses)
+	w.WriteString("]")
+}
+
+type SetCompNode struct {
+	Element Node
+	Clauses []*ComprehensionNode
+}
+
+func (n *SetCompNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *SetCompNode) Write(w *ASTWriter) {
+	w.WriteString("{" + safeString(n.Element))
+	writeClauses(w, n.Clauses)
+	w.WriteString("}")
+}
+
+type DictCompNode struct {
+	Key     Node
+	Value   Node
+	Clauses []*ComprehensionNode
+}
+
+func (n *DictCompNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *DictCompNode) Write(w *ASTWriter) {
+	w.WriteString("{" + safeString(n.Key) + ": " + safeString(n.Value))
+	writeClauses(w, n.Clauses)
+	w.WriteString("}")
+}
+
+type GeneratorExpNode struct {
+	Element Node
+	Clauses []*ComprehensionNode
+}
+
+func (n *GeneratorExpNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *GeneratorExpNode) Write(w *ASTWriter) {
+	w.WriteString("(" + safeString(n.Element))
+	writeClauses(w, n.Clauses)
+	w.WriteString(")")
+}
+
+type MatchNode struct {
+	Subject Node
+	Cases   []*MatchCaseNode
+}
+
+func (n *MatchNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchNode) Write(w *ASTWriter) {
+	w.WriteLine("match " + safeString(n.Subject) + ":")
+	w.Indent()
+	for _, c := range n.Cases {
+		c.Write(w)
+	}
+	w.Dedent()
+}
+
+type MatchCaseNode struct {
+	Pattern Node
+	Guard   Node
+	Body    Node
+}
+
+func (n *MatchCaseNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchCaseNode) Write(w *ASTWriter) {
+	header := "case " + safeString(n.Pattern)
+	if n.Guard != nil {
+		header += " if " + safeString(n.Guard)
+	}
+	w.WriteLine(header + ":")
+	w.Indent()
+	n.Body.Write(w)
+	w.Dedent()
+}
+
+// MatchValueNode matches subjects equal to a literal or dotted name.
+type MatchValueNode struct {
+	Value Node
+}
+
+func (n *MatchValueNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchValueNode) Write(w *ASTWriter) {
+	w.WriteString(safeString(n.Value))
+}
+
+// MatchSingletonNode matches None, True or False by identity.
+type MatchSingletonNode struct {
+	Value Node
+}
+
+func (n *MatchSingletonNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchSingletonNode) Write(w *ASTWriter) {
+	w.WriteString(safeString(n.Value))
+}
+
+// MatchAsNode binds the subject to Name if Pattern matches. Without a Pattern
+// it is a capture pattern, and without a Name as well it is the wildcard _.
+type MatchAsNode struct {
+	Pattern Node
+	Name    Node
+}
+
+func (n *MatchAsNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchAsNode) Write(w *ASTWriter) {
+	switch {
+	case n.Pattern == nil && n.Name == nil:
+		w.WriteString("_")
+	case n.Pattern == nil:
+		w.WriteString(safeString(n.Name))
+	default:
+		w.WriteString(safeString(n.Pattern) + " as " + safeString(n.Name))
+	}
+}
+
+type MatchOrNode struct {
+	Patterns []Node
+}
+
+func (n *MatchOrNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchOrNode) Write(w *ASTWriter) {
+	w.WriteString(joinNodes(n.Patterns, " | "))
+}
+
+type MatchSequenceNode struct {
+	Patterns []Node
+}
+
+func (n *MatchSequenceNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchSequenceNode) Write(w *ASTWriter) {
+	w.WriteString("[" + joinNodes(n.Patterns, ", ") + "]")
+}
+
+// MatchStarNode collects the rest of a sequence; Name is nil for *_.
+type MatchStarNode struct {
+	Name Node
+}
+
+func (n *MatchStarNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchStarNode) Write(w *ASTWriter) {
+	if n.Name == nil {
+		w.WriteString("*_")
+		return
+	}
+	w.WriteString("*" + safeString(n.Name))
+}
+
+type MatchMappingNode struct {
+	Keys     []Node
+	Patterns []Node
+	Rest     Node
+}
+
+func (n *MatchMappingNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchMappingNode) Write(w *ASTWriter) {
+	items := make([]string, 0, len(n.Keys)+1)
+	for i, key := range n.Keys {
+		items = append(items, safeString(key)+": "+safeString(n.Patterns[i]))
+	}
+	if n.Rest != nil {
+		items = append(items, "**"+safeString(n.Rest))
+	}
+	w.WriteString("{" + strings.Join(items, ", ") + "}")
+}
+
+type MatchClassNode struct {
+	Class       Node
+	Patterns    []Node
+	KwdAttrs    []Node
+	KwdPatterns []Node
+}
+
+func (n *MatchClassNode) String() string {
+	w := NewASTWriter()
+	n.Write(w)
+	return w.String()
+}
+
+func (n *MatchClassNode) Write(w *ASTWriter) {
+	items := []string{}
+	for _, pattern := range n.Patterns {
+		items = append(items, safeString(pattern))
+	}
+	for i, attr := range n.KwdAttrs {
+		items = append(items, safeString(attr)+"="+safeString(n.KwdPatterns[i]))
+	}
+	w.WriteString(safeString(n.Class) + "(" + strings.Join(items, ", ") + ")")
+}
+
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = safeString(n)
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,283 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"snek/object"
+	"snek/token"
+)
+
+func (in *Interpreter) evalAssignment(node *ast.AssignmentNode, env *object.Environment) (object.Object, error) {
+	if node.Operator != token.ASSIGN {
+		return object.NONE, in.evalAugmentedAssignment(node, env)
+	}
+
+	val, err := in.Eval(node.Value, env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range node.Targets {
+		if err := in.assign(target, val, env); err != nil {
+			return nil, err
+		}
+	}
+
+	return object.NONE, nil
+}
+
+func (in *Interpreter) assign(target ast.Node, val object.Object, env *object.Environment) error {
+	switch t := target.(type) {
+	case *ast.IdentifierNode:
+		env.Set(t.Name, val)
+		return nil
+	case *ast.TupleNode:
+		return in.unpack(t.Elements, val, env)
+	case *ast.ListNode:
+		return in.unpack(t.Elements, val, env)
+	case *ast.SliceNode:
+		container, err := in.Eval(t.Left, env)
+		if err != nil {
+			return err
+		}
+		index, err := in.Eval(t.Index, env)
+		if err != nil {
+			return err
+		}
+		return in.setItem(container, index, val)
+	case *ast.InfixNode:
+		if name, ok := t.Right.(*ast.IdentifierNode); ok && t.Operator == token.DOT {
+			obj, err := in.Eval(t.Left, env)
+			if err != nil {
+				return err
+			}
+			return in.setAttr(obj, name.Name, val)
+		}
+	}
+
+	return object.NewError(object.SyntaxError, "cannot assign to %s", target)
+}
+
+// unpack distributes the items of val over targets, collecting the surplus
+// into a list for a starred target.
+func (in *Interpreter) unpack(targets []ast.Node, val object.Object, env *object.Environment) error {
+	it, err := in.lookupIter(val)
+	if err != nil {
+		return err
+	}
+	if it == nil {
+		return object.NewError(object.TypeError, "cannot unpack non-iterable %s object", val.Type())
+	}
+
+	star := -1
+	for i, target := range targets {
+		if _, ok := target.(*ast.StarredNode); ok {
+			star = i
+		}
+	}
+
+	// Without a starred target, one item past the target count is enough to
+	// know there are too many.
+	items := []object.Object{}
+	for star >= 0 || len(items) <= len(targets) {
+		item, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			break
+		}
+		items = append(items, item)
+	}
+
+	if star < 0 {
+		if len(items) > len(targets) {
+			return object.NewError(object.ValueError, "too many values to unpack (expected %d)", len(targets))
+		} else if len(items) < len(targets) {
+			return object.NewError(object.ValueError, "not enough values to unpack (expected %d, got %d)", len(targets), len(items))
+		}
+
+		for i, target := range targets {
+			if err := in.assign(target, items[i], env); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	after := len(targets) - star - 1
+	if len(items) < star+after {
+		return object.NewError(object.ValueError, "not enough values to unpack (expected at least %d, got %d)", star+after, len(items))
+	}
+
+	for i, target := range targets[:star] {
+		if err := in.assign(target, items[i], env); err != nil {
+			return err
+		}
+	}
+
+	rest := append([]object.Object{}, items[star:len(items)-after]...)
+	if err := in.assign(targets[star].(*ast.StarredNode).Value, &object.List{Elements: rest}, env); err != nil {
+		return err
+	}
+
+	for i, target := range targets[star+1:] {
+		if err := in.assign(target, items[len(items)-after+i], env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *Interpreter) evalDel(node *ast.DelNode, env *object.Environment) (object.Object, error) {
+	for _, target := range node.Targets {
+		if err := in.del(target, env); err != nil {
+			return nil, err
+		}
+	}
+	return object.NONE, nil
+}
+
+func (in *Interpreter) del(target ast.Node, env *object.Environment) error {
+	switch t := target.(type) {
+	case *ast.IdentifierNode:
+		if !env.Delete(t.Name) {
+			if env.Unbound(t.Name) {
+				return unboundLocal(t.Name)
+			}
+			return object.NewError(object.NameError, "name '%s' is not defined", t.Name)
+		}
+		return nil
+	case *ast.TupleNode:
+		return in.delAll(t.Elements, env)
+	case *ast.ListNode:
+		return in.delAll(t.Elements, env)
+	case *ast.SliceNode:
+		container, err := in.Eval(t.Left, env)
+		if err != nil {
+			return err
+		}
+		index, err := in.Eval(t.Index, env)
+		if err != nil {
+			return err
+		}
+		return in.delItem(container, index)
+	case *ast.InfixNode:
+		if name, ok := t.Right.(*ast.IdentifierNode); ok && t.Operator == token.DOT {
+			obj, err := in.Eval(t.Left, env)
+			if err != nil {
+				return err
+			}
+			return in.delAttr(obj, name.Name)
+		}
+	}
+
+	return object.NewError(object.SyntaxError, "cannot delete %s", target)
+}
+
+func unboundLocal(name string) error {
+	return object.NewError(object.UnboundLocalError, "cannot access local variable '%s' where it is not associated with a value", name)
+}
+
+func (in *Interpreter) delAll(targets []ast.Node, env *object.Environment) error {
+	for _, target := range targets {
+		if err := in.del(target, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evalNamedExpr binds the target of ':=' outside any comprehensions, so the
+// value stays visible after the comprehension finishes.
+func (in *Interpreter) evalNamedExpr(node *ast.NamedExprNode, env *object.Environment) (object.Object, error) {
+	val, err := in.Eval(node.Value, env)
+	if err != nil {
+		return nil, err
+	}
+
+	env.NamedScope().Set(node.Target.(*ast.IdentifierNode).Name, val)
+	return val, nil
+}
+
+func (in *Interpreter) evalAugmentedAssignment(node *ast.AssignmentNode, env *object.Environment) error {
+	op, _ := token.AugmentedOp(node.Operator)
+
+	val, err := in.Eval(node.Value, env)
+	if err != nil {
+		return err
+	}
+
+	switch t := node.Targets[0].(type) {
+	case *ast.IdentifierNode:
+		cur, err := in.evalIdentifier(t, env)
+		if err != nil {
+			return err
+		}
+		res, err := in.inplaceOp(op, cur, val)
+		if err != nil {
+			return err
+		}
+		env.Set(t.Name, res)
+		return nil
+	case *ast.SliceNode:
+		container, err := in.Eval(t.Left, env)
+		if err != nil {
+			return err
+		}
+		index, err := in.Eval(t.Index, env)
+		if err != nil {
+			return err
+		}
+		cur, err := in.getItem(container, index)
+		if err != nil {
+			return err
+		}
+		res, err := in.inplaceOp(op, cur, val)
+		if err != nil {
+			return err
+		}
+		return in.setItem(container, index, res)
+	case *ast.InfixNode:
+		name, ok := t.Right.(*ast.IdentifierNode)
+		if !ok || t.Operator != token.DOT {
+			break
+		}
+		obj, err := in.Eval(t.Left, env)
+		if err != nil {
+			return err
+		}
+		cur, err := in.getAttr(obj, name.Name)
+		if err != nil {
+			return err
+		}
+		res, err := in.inplaceOp(op, cur, val)
+		if err != nil {
+			return err
+		}
+		return in.setAttr(obj, name.Name, res)
+	}
+
+	return object.NewError(object.SyntaxError, "illegal expression for augmented assignment")
+}
+
+// inplaceOp mutates lists for += and *=, like list.__iadd__ and list.__imul__.
+func (in *Interpreter) inplaceOp(op token.TokenType, left, right object.Object) (object.Object, error) {
+	if l, ok := left.(*object.List); ok {
+		switch op {
+		case token.PLUS:
+			items, err := in.collect(right)
+			if err != nil {
+				return nil, err
+			}
+			l.Elements = append(l.Elements, items...)
+			return l, nil
+		case token.STAR:
+			if n, ok := toNumber(right).(*object.Integer); ok {
+				l.Elements = repeat(l.Elements, int(max(n.Value, 0)))
+				return l, nil
+			}
+		}
+	}
+
+	return in.binaryOp(op, left, right)
+}
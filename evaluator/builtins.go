@@ -0,0 +1,820 @@
+package evaluator
+
+import (
+	"io"
+	"math"
+	"slices"
+	"snek/object"
+	"snek/token"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var builtins = map[string]object.Object{
+	"None":  object.NONE,
+	"True":  object.TRUE,
+	"False": object.FALSE,
+
+	"object":    object.ObjectClass,
+	"type":      typeClass,
+	"int":       intClass,
+	"bool":      boolClass,
+	"float":     floatClass,
+	"str":       strClass,
+	"tuple":     tupleClass,
+	"list":      listClass,
+	"dict":      dictClass,
+	"set":       setClass,
+	"range":     rangeClass,
+	"enumerate": enumerateClass,
+	"zip":       zipClass,
+	"map":       mapClass,
+	"filter":    filterClass,
+	"reversed":  reversedClass,
+
+	"staticmethod": staticmethodClass,
+	"classmethod":  classmethodClass,
+	"property":     propertyClass,
+
+	"BaseException":       object.BaseExceptionClass,
+	"GeneratorExit":       object.GeneratorExit,
+	"Exception":           object.ExceptionClass,
+	"ArithmeticError":     object.ArithmeticError,
+	"ZeroDivisionError":   object.ZeroDivisionError,
+	"OverflowError":       object.OverflowError,
+	"LookupError":         object.LookupError,
+	"IndexError":          object.IndexError,
+	"KeyError":            object.KeyError,
+	"NameError":           object.NameError,
+	"UnboundLocalError":   object.UnboundLocalError,
+	"ImportError":         object.ImportError,
+	"ModuleNotFoundError": object.ModuleNotFoundError,
+	"SyntaxError":         object.SyntaxError,
+	"IndentationError":    object.IndentationError,
+	"TabError":            object.TabError,
+	"AttributeError":      object.AttributeError,
+	"TypeError":           object.TypeError,
+	"ValueError":          object.ValueError,
+	"RuntimeError":        object.RuntimeError,
+	"NotImplementedError": object.NotImplementedError,
+	"RecursionError":      object.RecursionError,
+	"AssertionError":      object.AssertionError,
+	"StopIteration":       object.StopIteration,
+}
+
+func init() {
+	functions := map[string]func(*Interpreter, []object.Object, []object.Keyword) (object.Object, error){
+		"print": (*Interpreter).builtinPrint,
+		"len":   (*Interpreter).builtinLen,
+		"repr":  (*Interpreter).builtinRepr,
+		"iter":  (*Interpreter).builtinIter,
+		"next":  (*Interpreter).builtinNext,
+		"sum":   (*Interpreter).builtinSum,
+		"min": func(in *Interpreter, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			return in.minMax("min", args, kwargs)
+		},
+		"max": func(in *Interpreter, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			return in.minMax("max", args, kwargs)
+		},
+		"any":        (*Interpreter).builtinAny,
+		"all":        (*Interpreter).builtinAll,
+		"abs":        (*Interpreter).builtinAbs,
+		"sorted":     (*Interpreter).builtinSorted,
+		"isinstance": (*Interpreter).builtinIsinstance,
+	}
+	for name, fn := range functions {
+		builtins[name] = &object.Builtin{Name: name, Fn: builtin(fn)}
+	}
+
+	typeClass.Constructor = builtin((*Interpreter).newType)
+	intClass.Constructor = builtin((*Interpreter).newInt)
+	boolClass.Constructor = builtin((*Interpreter).newBool)
+	floatClass.Constructor = builtin((*Interpreter).newFloat)
+	strClass.Constructor = builtin((*Interpreter).newStr)
+	tupleClass.Constructor = builtin((*Interpreter).newTuple)
+	listClass.Constructor = builtin((*Interpreter).newList)
+	dictClass.Constructor = builtin((*Interpreter).newDict)
+	setClass.Constructor = builtin((*Interpreter).newSet)
+	rangeClass.Constructor = builtin((*Interpreter).newRange)
+	enumerateClass.Constructor = builtin((*Interpreter).newEnumerate)
+	zipClass.Constructor = builtin((*Interpreter).newZip)
+	mapClass.Constructor = builtin((*Interpreter).newMap)
+	filterClass.Constructor = builtin((*Interpreter).newFilter)
+	reversedClass.Constructor = builtin((*Interpreter).newReversed)
+	staticmethodClass.Constructor = builtin((*Interpreter).newStaticMethod)
+	classmethodClass.Constructor = builtin((*Interpreter).newClassMethod)
+	propertyClass.Constructor = builtin((*Interpreter).newProperty)
+}
+
+func (in *Interpreter) builtinPrint(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	sep, end := " ", "\n"
+	for _, kw := range kwargs {
+		var target *string
+		switch kw.Name {
+		case "sep":
+			target = &sep
+		case "end":
+			target = &end
+		default:
+			return nil, object.NewError(object.TypeError, "print() got an unexpected keyword argument '%s'", kw.Name)
+		}
+
+		if kw.Value == object.NONE {
+			continue
+		}
+		s, ok := kw.Value.(*object.String)
+		if !ok {
+			return nil, object.NewError(object.TypeError, "%s must be None or a string, not %s", kw.Name, kw.Value.Type())
+		}
+		*target = s.Value
+	}
+
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		s, err := in.str(arg)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = s
+	}
+
+	io.WriteString(in.stdout, strings.Join(parts, sep)+end)
+	return object.NONE, nil
+}
+
+func (in *Interpreter) builtinLen(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("len", args, kwargs, 1, "obj")
+	if err != nil {
+		return nil, err
+	}
+	n, err := in.lengthOf(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.Integer{Value: int64(n)}, nil
+}
+
+func (in *Interpreter) lengthOf(obj object.Object) (int, error) {
+	switch obj := obj.(type) {
+	case *object.String:
+		return utf8.RuneCountInString(obj.Value), nil
+	case *object.List:
+		return len(obj.Elements), nil
+	case *object.Tuple:
+		return len(obj.Elements), nil
+	case *object.Dict:
+		return obj.Len(), nil
+	case *object.DictView:
+		return obj.Dict.Len(), nil
+	case *object.Range:
+		return int(obj.Len()), nil
+	case *object.Set:
+		return obj.Len(), nil
+	}
+
+	if res, ok, err := in.callSpecial(obj, "__len__"); ok {
+		if err != nil {
+			return 0, err
+		}
+		n, isInt := toNumber(res).(*object.Integer)
+		if !isInt {
+			return 0, object.NewError(object.TypeError, "'%s' object cannot be interpreted as an integer", res.Type())
+		}
+		if n.Value < 0 {
+			return 0, object.NewError(object.ValueError, "__len__() should return >= 0")
+		}
+		return int(n.Value), nil
+	}
+	return 0, object.NewError(object.TypeError, "object of type '%s' has no len()", obj.Type())
+}
+
+func (in *Interpreter) builtinRepr(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("repr", args, kwargs, 1, "obj")
+	if err != nil {
+		return nil, err
+	}
+	s, err := in.repr(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.String{Value: s}, nil
+}
+
+// Repr returns repr(obj), calling __repr__ on user-defined objects.
+func (in *Interpreter) Repr(obj object.Object) (string, error) {
+	return in.repr(obj)
+}
+
+// BuiltinNames returns the names of the builtins, sorted.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (in *Interpreter) repr(obj object.Object) (string, error) {
+	switch obj := obj.(type) {
+	case *object.List:
+		if in.reprActive[obj] {
+			return "[...]", nil
+		}
+		in.reprActive[obj] = true
+		defer delete(in.reprActive, obj)
+
+		s, err := in.reprAll(obj.Elements)
+		return "[" + s + "]", err
+	case *object.Tuple:
+		s, err := in.reprAll(obj.Elements)
+		if len(obj.Elements) == 1 {
+			s += ","
+		}
+		return "(" + s + ")", err
+	case *object.Set:
+		if obj.Len() == 0 {
+			return "set()", nil
+		}
+		if in.reprActive[obj] {
+			return "{...}", nil
+		}
+		in.reprActive[obj] = true
+		defer delete(in.reprActive, obj)
+
+		items := make([]object.Object, obj.Len())
+		for i := range items {
+			items[i] = obj.Item(i)
+		}
+		s, err := in.reprAll(items)
+		return "{" + s + "}", err
+	case *object.Dict:
+		if in.reprActive[obj] {
+			return "{...}", nil
+		}
+		in.reprActive[obj] = true
+		defer delete(in.reprActive, obj)
+
+		parts := make([]string, obj.Len())
+		for i := range parts {
+			pair := obj.PairAt(i)
+			k, err := in.repr(pair.Key)
+			if err != nil {
+				return "", err
+			}
+			v, err := in.repr(pair.Value)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = k + ": " + v
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	}
+
+	if s, ok, err := in.callSpecialString(obj, "__repr__"); ok {
+		return s, err
+	}
+	return obj.Inspect(), nil
+}
+
+func (in *Interpreter) reprAll(objs []object.Object) (string, error) {
+	parts := make([]string, len(objs))
+	for i, obj := range objs {
+		s, err := in.repr(obj)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func (in *Interpreter) str(obj object.Object) (string, error) {
+	if s, ok, err := in.callSpecialString(obj, "__str__"); ok {
+		return s, err
+	}
+
+	switch obj := obj.(type) {
+	case *object.String:
+		return obj.Value, nil
+	case *object.Exception:
+		return obj.Message(), nil
+	}
+	return in.repr(obj)
+}
+
+func (in *Interpreter) builtinIter(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("iter", args, kwargs, 1, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	return in.iterate(bound[0])
+}
+
+func (in *Interpreter) builtinNext(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("next", args, kwargs, 1, "iterator", "default")
+	if err != nil {
+		return nil, err
+	}
+
+	it, ok := bound[0].(object.Iterator)
+	if !ok && hasSpecial(bound[0], "__next__") {
+		it, ok = &instanceIterator{in: in, obj: bound[0]}, true
+	}
+	if !ok {
+		return nil, object.NewError(object.TypeError, "'%s' object is not an iterator", bound[0].Type())
+	}
+
+	// Resume generators directly so StopIteration carries their return value.
+	if gen, ok := it.(*object.Generator); ok {
+		item, err := gen.Resume(object.NONE, nil)
+		if exc, ok := err.(*object.Exception); ok && exc.Class == object.StopIteration && gen.Finished() && bound[1] != nil {
+			return bound[1], nil
+		}
+		return item, err
+	}
+
+	item, err := it.Next()
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		if bound[1] != nil {
+			return bound[1], nil
+		}
+		return nil, &object.Exception{Class: object.StopIteration}
+	}
+	return item, nil
+}
+
+func (in *Interpreter) builtinSum(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("sum", args, kwargs, 1, "iterable", "start")
+	if err != nil {
+		return nil, err
+	}
+
+	var total object.Object = &object.Integer{Value: 0}
+	if bound[1] != nil {
+		if _, ok := bound[1].(*object.String); ok {
+			return nil, object.NewError(object.TypeError, "sum() can't sum strings [use ''.join(seq) instead]")
+		}
+		total = bound[1]
+	}
+
+	it, err := in.iterate(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	for {
+		item, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return total, nil
+		}
+		if total, err = in.binaryOp(token.PLUS, total, item); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (in *Interpreter) minMax(name string, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	var key, def object.Object
+	for _, kw := range kwargs {
+		switch kw.Name {
+		case "key":
+			key = kw.Value
+		case "default":
+			def = kw.Value
+		default:
+			return nil, object.NewError(object.TypeError, "%s() got an unexpected keyword argument '%s'", name, kw.Name)
+		}
+	}
+
+	var items []object.Object
+	switch len(args) {
+	case 0:
+		return nil, object.NewError(object.TypeError, "%s expected at least 1 argument, got 0", name)
+	case 1:
+		collected, err := in.collect(args[0])
+		if err != nil {
+			return nil, err
+		}
+		items = collected
+	default:
+		if def != nil {
+			return nil, object.NewError(object.TypeError, "Cannot specify a default for %s() with multiple positional arguments", name)
+		}
+		items = args
+	}
+
+	if len(items) == 0 {
+		if def != nil {
+			return def, nil
+		}
+		return nil, object.NewError(object.ValueError, "%s() iterable argument is empty", name)
+	}
+
+	op := token.LT
+	if name == "max" {
+		op = token.GT
+	}
+
+	best, bestKey := items[0], items[0]
+	if key != nil && key != object.NONE {
+		k, err := in.callObject(key, []object.Object{best}, nil)
+		if err != nil {
+			return nil, err
+		}
+		bestKey = k
+	}
+
+	for _, item := range items[1:] {
+		itemKey := item
+		if key != nil && key != object.NONE {
+			k, err := in.callObject(key, []object.Object{item}, nil)
+			if err != nil {
+				return nil, err
+			}
+			itemKey = k
+		}
+
+		better, err := in.binaryOp(op, itemKey, bestKey)
+		if err != nil {
+			return nil, err
+		}
+		if better == object.TRUE {
+			best, bestKey = item, itemKey
+		}
+	}
+	return best, nil
+}
+
+func (in *Interpreter) builtinAny(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	return in.anyAll("any", true, args, kwargs)
+}
+
+func (in *Interpreter) builtinAll(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	return in.anyAll("all", false, args, kwargs)
+}
+
+// anyAll returns stopOn as soon as an item's truthiness equals it.
+func (in *Interpreter) anyAll(name string, stopOn bool, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs(name, args, kwargs, 1, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	it, err := in.iterate(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	for {
+		item, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return object.NativeBool(!stopOn), nil
+		}
+		truthy, err := in.isTruthy(item)
+		if err != nil {
+			return nil, err
+		}
+		if truthy == stopOn {
+			return object.NativeBool(stopOn), nil
+		}
+	}
+}
+
+func (in *Interpreter) builtinAbs(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("abs", args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch x := toNumber(bound[0]).(type) {
+	case *object.Integer:
+		if x.Value == math.MinInt64 {
+			return nil, intOverflow("abs()")
+		}
+		if x.Value < 0 {
+			return &object.Integer{Value: -x.Value}, nil
+		}
+		return x, nil
+	case *object.Float:
+		return &object.Float{Value: math.Abs(x.Value)}, nil
+	}
+	return nil, object.NewError(object.TypeError, "bad operand type for abs(): '%s'", bound[0].Type())
+}
+
+func (in *Interpreter) builtinSorted(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewError(object.TypeError, "sorted expected 1 argument, got %d", len(args))
+	}
+	bound, err := bindArgs("sorted", nil, kwargs, 0, "key", "reverse")
+	if err != nil {
+		return nil, err
+	}
+	items, err := in.collect(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.List{Elements: items}, in.sortObjects(items, bound[0], bound[1])
+}
+
+// sortObjects stably sorts items in place, comparing keys with '<'.
+func (in *Interpreter) sortObjects(items []object.Object, key, reverse object.Object) error {
+	keys := items
+	if key != nil && key != object.NONE {
+		keys = make([]object.Object, len(items))
+		for i, item := range items {
+			k, err := in.callObject(key, []object.Object{item}, nil)
+			if err != nil {
+				return err
+			}
+			keys[i] = k
+		}
+	}
+
+	descending := false
+	if reverse != nil {
+		truthy, err := in.isTruthy(reverse)
+		if err != nil {
+			return err
+		}
+		descending = truthy
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	var sortErr error
+	less := func(a, b object.Object) bool {
+		res, err := in.binaryOp(token.LT, a, b)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return res == object.TRUE
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if descending {
+			a, b = b, a
+		}
+		if less(keys[a], keys[b]) {
+			return -1
+		} else if less(keys[b], keys[a]) {
+			return 1
+		}
+		return 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	sorted := make([]object.Object, len(items))
+	for i, j := range order {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+func (in *Interpreter) builtinIsinstance(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("isinstance", args, kwargs, 2, "obj", "class_or_tuple")
+	if err != nil {
+		return nil, err
+	}
+
+	classes := []object.Object{bound[1]}
+	if tuple, ok := bound[1].(*object.Tuple); ok {
+		classes = tuple.Elements
+	}
+	for _, c := range classes {
+		cls, ok := c.(*object.Class)
+		if !ok {
+			return nil, object.NewError(object.TypeError, "isinstance() arg 2 must be a type or tuple of types")
+		}
+		if isInstance(bound[0], cls) {
+			return object.TRUE, nil
+		}
+	}
+	return object.FALSE, nil
+}
+
+func (in *Interpreter) newType(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("type", args, kwargs, 1, "object")
+	if err != nil {
+		return nil, err
+	}
+	return classOf(bound[0]), nil
+}
+
+func (in *Interpreter) newInt(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("int", args, kwargs, 0, "x", "base")
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := bound[0].(type) {
+	case nil:
+		return &object.Integer{Value: 0}, nil
+	case *object.String:
+		base := int64(10)
+		if bound[1] != nil {
+			if base, err = toInt(bound[1]); err != nil {
+				return nil, err
+			}
+		}
+		literal := strings.ReplaceAll(strings.TrimSpace(x.Value), "_", "")
+		value, err := strconv.ParseInt(literal, int(base), 64)
+		if err != nil {
+			return nil, object.NewError(object.ValueError, "invalid literal for int() with base %d: %s", base, x.Inspect())
+		}
+		return &object.Integer{Value: value}, nil
+	case *object.Float:
+		if math.IsInf(x.Value, 0) || math.IsNaN(x.Value) {
+			return nil, object.NewError(object.OverflowError, "cannot convert float %s to integer", x.Inspect())
+		}
+		return &object.Integer{Value: int64(x.Value)}, nil
+	}
+
+	if bound[1] != nil {
+		return nil, object.NewError(object.TypeError, "int() can't convert non-string with explicit base")
+	}
+	if i, ok := toNumber(bound[0]).(*object.Integer); ok {
+		return i, nil
+	}
+	return nil, object.NewError(object.TypeError, "int() argument must be a string or a number, not '%s'", bound[0].Type())
+}
+
+func (in *Interpreter) newFloat(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("float", args, kwargs, 0, "x")
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := bound[0].(type) {
+	case nil:
+		return &object.Float{Value: 0}, nil
+	case *object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(x.Value), 64)
+		if err != nil {
+			return nil, object.NewError(object.ValueError, "could not convert string to float: %s", x.Inspect())
+		}
+		return &object.Float{Value: value}, nil
+	}
+
+	if n := toNumber(bound[0]); isNumber(n) {
+		return &object.Float{Value: toFloat(n)}, nil
+	}
+	return nil, object.NewError(object.TypeError, "float() argument must be a string or a real number, not '%s'", bound[0].Type())
+}
+
+func (in *Interpreter) newBool(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("bool", args, kwargs, 0, "x")
+	if err != nil || bound[0] == nil {
+		return object.FALSE, err
+	}
+	truthy, err := in.isTruthy(bound[0])
+	return object.NativeBool(truthy), err
+}
+
+func (in *Interpreter) newStr(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("str", args, kwargs, 0, "object")
+	if err != nil {
+		return nil, err
+	}
+	if bound[0] == nil {
+		return &object.String{}, nil
+	}
+	s, err := in.str(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.String{Value: s}, nil
+}
+
+func (in *Interpreter) newTuple(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("tuple", args, kwargs, 0, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	if bound[0] == nil {
+		return &object.Tuple{Elements: []object.Object{}}, nil
+	}
+	items, err := in.collect(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.Tuple{Elements: items}, nil
+}
+
+func (in *Interpreter) newList(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("list", args, kwargs, 0, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	if bound[0] == nil {
+		return &object.List{Elements: []object.Object{}}, nil
+	}
+	items, err := in.collect(bound[0])
+	if err != nil {
+		return nil, err
+	}
+	return &object.List{Elements: items}, nil
+}
+
+func (in *Interpreter) newDict(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("dict", args, nil, 0, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	d := object.NewDict()
+	return d, in.updateDict(d, bound[0], kwargs)
+}
+
+func (in *Interpreter) newSet(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("set", args, kwargs, 0, "iterable")
+	if err != nil {
+		return nil, err
+	}
+	s := object.NewSet()
+	if bound[0] == nil {
+		return s, nil
+	}
+	return s, in.updateSet(s, bound[0])
+}
+
+func (in *Interpreter) newRange(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	if len(kwargs) > 0 {
+		return nil, object.NewError(object.TypeError, "range() takes no keyword arguments")
+	}
+	if len(args) == 0 || len(args) > 3 {
+		return nil, object.NewError(object.TypeError, "range expected at least 1 argument, got %d", len(args))
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		n, err := toInt(arg)
+		if err != nil {
+			return nil, err
+		}
+		bounds[i] = n
+	}
+
+	r := &object.Range{Step: 1}
+	switch len(bounds) {
+	case 1:
+		r.Stop = bounds[0]
+	case 3:
+		if bounds[2] == 0 {
+			return nil, object.NewError(object.ValueError, "range() arg 3 must not be zero")
+		}
+		r.Step = bounds[2]
+		fallthrough
+	default:
+		r.Start, r.Stop = bounds[0], bounds[1]
+	}
+	return r, nil
+}
+
+func (in *Interpreter) newStaticMethod(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("staticmethod", args, kwargs, 1, "function")
+	if err != nil {
+		return nil, err
+	}
+	return &object.StaticMethod{Function: bound[0]}, nil
+}
+
+func (in *Interpreter) newClassMethod(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("classmethod", args, kwargs, 1, "function")
+	if err != nil {
+		return nil, err
+	}
+	return &object.ClassMethod{Function: bound[0]}, nil
+}
+
+func (in *Interpreter) newProperty(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("property", args, kwargs, 0, "fget", "fset", "fdel", "doc")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &object.Property{}
+	for i, target := range []*object.Object{&p.Get, &p.Set, &p.Delete, &p.Doc} {
+		if bound[i] != nil && bound[i] != object.NONE {
+			*target = bound[i]
+		}
+	}
+
+	// Like Python, take the docstring from the getter if none is given.
+	if p.Doc == nil && p.Get != nil {
+		if doc, err := in.getAttr(p.Get, "__doc__"); err == nil && doc != object.NONE {
+			p.Doc = doc
+		}
+	}
+	return p, nil
+}
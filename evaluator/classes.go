@@ -0,0 +1,295 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"snek/object"
+)
+
+func (in *Interpreter) evalClassDef(node *ast.ClassDefNode, env *object.Environment) (object.Object, error) {
+	decorators, err := in.evalElements(node.Decorators, env)
+	if err != nil {
+		return nil, err
+	}
+
+	name := node.Name.String()
+	bases := []*object.Class{}
+	for _, b := range node.Bases {
+		if _, ok := b.(*ast.KeywordNode); ok {
+			return nil, object.NewError(object.TypeError, "class keyword arguments are not supported")
+		}
+
+		val, err := in.Eval(b, env)
+		if err != nil {
+			return nil, err
+		}
+		base, ok := val.(*object.Class)
+		if !ok {
+			return nil, object.NewError(object.TypeError, "bases must be types, not %s", val.Type())
+		}
+		if base.Constructor != nil {
+			return nil, object.NewError(object.TypeError, "subclassing built-in type '%s' is not supported", base.Name)
+		}
+		for _, other := range bases {
+			if other == base {
+				return nil, object.NewError(object.TypeError, "duplicate base class %s", base.Name)
+			}
+		}
+		bases = append(bases, base)
+	}
+	if len(bases) == 0 {
+		bases = append(bases, object.ObjectClass)
+	}
+
+	body := object.NewClassEnvironment(env)
+	if mod, ok := env.Get("__name__"); ok {
+		body.Set("__module__", mod)
+	}
+	if doc := docstring(node.Body); doc != nil {
+		body.Set("__doc__", doc)
+	}
+	if _, err := in.Eval(node.Body, body); err != nil {
+		return nil, err
+	}
+
+	cls := object.NewClass(name, bases...)
+	for _, attr := range body.Names() {
+		cls.Attrs[attr], _ = body.Get(attr)
+	}
+	// Equal instances must hash alike, so defining __eq__ alone makes them unhashable.
+	if _, ok := cls.Attrs["__eq__"]; ok {
+		if _, ok := cls.Attrs["__hash__"]; !ok {
+			cls.Attrs["__hash__"] = object.NONE
+		}
+	}
+
+	res, err := in.applyDecorators(decorators, cls)
+	if err != nil {
+		return nil, err
+	}
+	env.Set(name, res)
+	return object.NONE, nil
+}
+
+// docstring returns the string literal that starts body, or nil. Every line
+// of a block is a block of its own, so nested blocks are unwrapped.
+func docstring(body ast.Node) object.Object {
+	stmt := body
+	for {
+		block, ok := stmt.(*ast.BlockNode)
+		if !ok {
+			break
+		}
+		if len(block.Statements) == 0 {
+			return nil
+		}
+		stmt = block.Statements[0]
+	}
+
+	if exprs, ok := stmt.(*ast.ExpressionsNode); ok && len(exprs.Expressions) == 1 {
+		stmt = exprs.Expressions[0]
+	}
+	if s, ok := stmt.(*ast.StringNode); ok {
+		return &object.String{Value: s.Value}
+	}
+	return nil
+}
+
+// instantiate creates an instance of a user-defined class and runs its __init__.
+func (in *Interpreter) instantiate(cls *object.Class, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	var obj object.Object
+	if cls.IsSubclass(object.BaseExceptionClass) {
+		obj = &object.Exception{Class: cls, Args: args}
+	} else {
+		obj = object.NewInstance(cls)
+	}
+
+	init, ok := cls.LookupAttr("__init__")
+	if !ok {
+		if len(kwargs) > 0 {
+			return nil, object.NewError(object.TypeError, "%s() takes no keyword arguments", cls.Name)
+		}
+		if _, ok := obj.(*object.Instance); ok && len(args) > 0 {
+			return nil, object.NewError(object.TypeError, "%s() takes no arguments", cls.Name)
+		}
+		return obj, nil
+	}
+
+	method, err := in.bindAttr(obj, init)
+	if err != nil {
+		return nil, err
+	}
+	res, err := in.callObject(method, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if res != object.NONE {
+		return nil, object.NewError(object.TypeError, "__init__() should return None, not '%s'", res.Type())
+	}
+	return obj, nil
+}
+
+// bindAttr turns a class attribute into the value seen through obj: functions
+// become bound methods, and static methods, class methods and properties
+// are resolved.
+func (in *Interpreter) bindAttr(obj, val object.Object) (object.Object, error) {
+	switch val := val.(type) {
+	case *object.Function, *object.Builtin:
+		return &object.BoundMethod{Self: obj, Function: val}, nil
+	case *object.StaticMethod:
+		return val.Function, nil
+	case *object.ClassMethod:
+		return &object.BoundMethod{Self: classOf(obj), Function: val.Function}, nil
+	case *object.Property:
+		if val.Get == nil {
+			return nil, object.NewError(object.AttributeError, "property of '%s' object has no getter", obj.Type())
+		}
+		return in.callObject(val.Get, []object.Object{obj}, nil)
+	}
+	return val, nil
+}
+
+// bindClassAttr is bindAttr for attributes looked up on the class itself.
+func bindClassAttr(cls *object.Class, val object.Object) object.Object {
+	switch val := val.(type) {
+	case *object.StaticMethod:
+		return val.Function
+	case *object.ClassMethod:
+		return &object.BoundMethod{Self: cls, Function: val.Function}
+	}
+	return val
+}
+
+// setInstanceAttr stores an attribute in an instance's dict unless a
+// property on its class handles the assignment.
+func (in *Interpreter) setInstanceAttr(obj object.Object, cls *object.Class, attrs map[string]object.Object, name string, value object.Object) error {
+	if val, ok := cls.LookupAttr(name); ok {
+		if prop, ok := val.(*object.Property); ok {
+			if prop.Set == nil {
+				return object.NewError(object.AttributeError, "property '%s' of '%s' object has no setter", name, obj.Type())
+			}
+			_, err := in.callObject(prop.Set, []object.Object{obj, value}, nil)
+			return err
+		}
+	}
+
+	attrs[name] = value
+	return nil
+}
+
+// delInstanceAttr removes an attribute from an instance's dict, or calls the
+// deleter of a property on its class.
+func (in *Interpreter) delInstanceAttr(obj object.Object, cls *object.Class, attrs map[string]object.Object, name string) error {
+	if val, ok := cls.LookupAttr(name); ok {
+		if prop, ok := val.(*object.Property); ok {
+			if prop.Delete == nil {
+				return object.NewError(object.AttributeError, "property '%s' of '%s' object has no deleter", name, obj.Type())
+			}
+			_, err := in.callObject(prop.Delete, []object.Object{obj}, nil)
+			return err
+		}
+	}
+
+	if _, ok := attrs[name]; !ok {
+		return object.NewError(object.AttributeError, "'%s' object has no attribute '%s'", obj.Type(), name)
+	}
+	delete(attrs, name)
+	return nil
+}
+
+// callSpecial calls a special method such as __len__ defined by the class of
+// a user-defined object. ok is false if there is no such method.
+func (in *Interpreter) callSpecial(obj object.Object, name string, args ...object.Object) (res object.Object, ok bool, err error) {
+	val, ok := specialAttr(obj, name)
+	if !ok {
+		return nil, false, nil
+	}
+	method, err := in.bindAttr(obj, val)
+	if err != nil {
+		return nil, true, err
+	}
+	res, err = in.callObject(method, args, nil)
+	return res, true, err
+}
+
+// callSpecialString calls a special method that must return a str, such as __repr__.
+func (in *Interpreter) callSpecialString(obj object.Object, name string) (string, bool, error) {
+	res, ok, err := in.callSpecial(obj, name)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+	s, isStr := res.(*object.String)
+	if !isStr {
+		return "", true, object.NewError(object.TypeError, "%s returned non-string (type %s)", name, res.Type())
+	}
+	return s.Value, true, nil
+}
+
+// instanceIterator adapts an object with a __next__ method to object.Iterator.
+type instanceIterator struct {
+	in  *Interpreter
+	obj object.Object
+}
+
+func (it *instanceIterator) Type() object.ObjectType { return it.obj.Type() }
+func (it *instanceIterator) Inspect() string         { return it.obj.Inspect() }
+
+func (it *instanceIterator) Next() (object.Object, error) {
+	item, _, err := it.in.callSpecial(it.obj, "__next__")
+	if exc, ok := err.(*object.Exception); ok && exc.Class.IsSubclass(object.StopIteration) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// instanceIter implements iter() for user-defined objects.
+func (in *Interpreter) instanceIter(obj object.Object) (object.Iterator, error) {
+	res, ok, err := in.callSpecial(obj, "__iter__")
+	if !ok || err != nil {
+		return nil, err
+	}
+
+	if it, ok := res.(object.Iterator); ok {
+		return it, nil
+	}
+	if hasSpecial(res, "__next__") {
+		return &instanceIterator{in: in, obj: res}, nil
+	}
+	return nil, object.NewError(object.TypeError, "iter() returned non-iterator of type '%s'", res.Type())
+}
+
+func hasSpecial(obj object.Object, name string) bool {
+	_, ok := specialAttr(obj, name)
+	return ok
+}
+
+// specialAttr looks up a special method on the class of a user-defined object.
+func specialAttr(obj object.Object, name string) (object.Object, bool) {
+	switch obj := obj.(type) {
+	case *object.Instance:
+		return obj.Class.LookupAttr(name)
+	case *object.Exception:
+		return obj.Class.LookupAttr(name)
+	}
+	return nil, false
+}
+
+func propertyAttr(prop *object.Property, name string) (object.Object, bool) {
+	var val object.Object
+	switch name {
+	case "fget":
+		val = prop.Get
+	case "fset":
+		val = prop.Set
+	case "fdel":
+		val = prop.Delete
+	case "__doc__":
+		val = prop.Doc
+	default:
+		return nil, false
+	}
+
+	if val == nil {
+		return object.NONE, true
+	}
+	return val, true
+}
@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"snek/object"
+)
+
+func (in *Interpreter) evalListComp(node *ast.ListCompNode, env *object.Environment) (object.Object, error) {
+	list := &object.List{Elements: []object.Object{}}
+	err := in.evalComprehension(node.Clauses, env, func(scope *object.Environment) error {
+		val, err := in.Eval(node.Element, scope)
+		if err != nil {
+			return err
+		}
+		list.Elements = append(list.Elements, val)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (in *Interpreter) evalSetComp(node *ast.SetCompNode, env *object.Environment) (object.Object, error) {
+	set := object.NewSet()
+	err := in.evalComprehension(node.Clauses, env, func(scope *object.Environment) error {
+		val, err := in.Eval(node.Element, scope)
+		if err != nil {
+			return err
+		}
+		return in.setAdd(set, val)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (in *Interpreter) evalDictComp(node *ast.DictCompNode, env *object.Environment) (object.Object, error) {
+	dict := object.NewDict()
+	err := in.evalComprehension(node.Clauses, env, func(scope *object.Environment) error {
+		key, err := in.Eval(node.Key, scope)
+		if err != nil {
+			return err
+		}
+		val, err := in.Eval(node.Value, scope)
+		if err != nil {
+			return err
+		}
+		return in.dictSet(dict, key, val)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dict, nil
+}
+
+// evalGeneratorExp returns a generator that runs the comprehension lazily.
+// As in Python, the first iterable is evaluated straight away.
+func (in *Interpreter) evalGeneratorExp(node *ast.GeneratorExpNode, env *object.Environment) (object.Object, error) {
+	it, err := in.firstIterator(node.Clauses, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.NewGenerator("<genexpr>", func(yield object.YieldFunc) (object.Object, error) {
+		scope := object.NewComprehensionEnvironment(env)
+		err := in.runClauses(node.Clauses, it, scope, func(scope *object.Environment) error {
+			val, err := in.Eval(node.Element, scope)
+			if err != nil {
+				return err
+			}
+			_, err = yield(val)
+			return err
+		})
+		return object.NONE, err
+	}), nil
+}
+
+// evalComprehension calls emit once for every binding of the loop targets that
+// passes the conditions. The targets live in a scope of their own, so they do
+// not leak into env.
+func (in *Interpreter) evalComprehension(clauses []*ast.ComprehensionNode, env *object.Environment, emit func(*object.Environment) error) error {
+	it, err := in.firstIterator(clauses, env)
+	if err != nil {
+		return err
+	}
+	return in.runClauses(clauses, it, object.NewComprehensionEnvironment(env), emit)
+}
+
+// firstIterator evaluates the outermost iterable, which belongs to the
+// enclosing scope rather than the comprehension's.
+func (in *Interpreter) firstIterator(clauses []*ast.ComprehensionNode, env *object.Environment) (object.Iterator, error) {
+	iterable, err := in.Eval(clauses[0].Values, env)
+	if err != nil {
+		return nil, err
+	}
+	return in.iterate(iterable)
+}
+
+func (in *Interpreter) runClauses(clauses []*ast.ComprehensionNode, it object.Iterator, scope *object.Environment, emit func(*object.Environment) error) error {
+	clause := clauses[0]
+	for {
+		item, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return nil
+		}
+
+		if err := in.assign(clause.Targets, item, scope); err != nil {
+			return err
+		}
+
+		ok, err := in.allTrue(clause.Ifs, scope)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if len(clauses) == 1 {
+			err = emit(scope)
+		} else {
+			err = in.runInnerClauses(clauses[1:], scope, emit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (in *Interpreter) runInnerClauses(clauses []*ast.ComprehensionNode, scope *object.Environment, emit func(*object.Environment) error) error {
+	iterable, err := in.Eval(clauses[0].Values, scope)
+	if err != nil {
+		return err
+	}
+	it, err := in.iterate(iterable)
+	if err != nil {
+		return err
+	}
+	return in.runClauses(clauses, it, scope, emit)
+}
+
+func (in *Interpreter) allTrue(conditions []ast.Node, env *object.Environment) (bool, error) {
+	for _, cond := range conditions {
+		val, err := in.Eval(cond, env)
+		if err != nil {
+			return false, err
+		}
+		truthy, err := in.isTruthy(val)
+		if err != nil || !truthy {
+			return false, err
+		}
+	}
+	return true, nil
+}
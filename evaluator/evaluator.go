@@ -3,6 +3,8 @@ package evaluator
 import (
 	"fmt"
 	"snek/ast"
+	"snek/object"
+	"strconv"
 	"strings"
 )
 
@@ -35,10 +37,137 @@ func DebugPrint(node ast.Node, depth int) {
 		DebugPrint(n.Right, depth+1)
 	case *ast.AssignmentNode:
 		indentPrint("assignment", depth)
-		DebugPrint(n.Target, depth+1)
+		DebugPrintAll(n.Targets, depth+1)
+		DebugPrint(n.Value, depth+1)
+	case *ast.TupleNode:
+		indentPrint("tuple", depth)
+		DebugPrintAll(n.Elements, depth+1)
+	case *ast.ListNode:
+		indentPrint("list", depth)
+		DebugPrintAll(n.Elements, depth+1)
+	case *ast.StarredNode:
+		indentPrint("starred", depth)
+		DebugPrint(n.Value, depth+1)
+	case *ast.DoubleStarredNode:
+		indentPrint("doublestarred", depth)
 		DebugPrint(n.Value, depth+1)
 	case *ast.IdentifierNode:
 		indentPrint("identifier", depth)
+	case *ast.StringNode:
+		indentPrint("string", depth)
+	case *ast.DictNode:
+		indentPrint("dict", depth)
+		for i := range n.Keys {
+			DebugPrint(n.Keys[i], depth+1)
+			DebugPrint(n.Values[i], depth+1)
+		}
+	case *ast.CallNode:
+		indentPrint("call", depth)
+		DebugPrint(n.Function, depth+1)
+		DebugPrintAll(n.Args, depth+1)
+	case *ast.KeywordNode:
+		indentPrint("keyword", depth)
+		DebugPrint(n.Value, depth+1)
+	case *ast.SetNode:
+		indentPrint("set", depth)
+		DebugPrintAll(n.Elements, depth+1)
+	case *ast.ListCompNode:
+		indentPrint("listcomp", depth)
+		DebugPrint(n.Element, depth+1)
+		debugPrintClauses(n.Clauses, depth+1)
+	case *ast.SetCompNode:
+		indentPrint("setcomp", depth)
+		DebugPrint(n.Element, depth+1)
+		debugPrintClauses(n.Clauses, depth+1)
+	case *ast.DictCompNode:
+		indentPrint("dictcomp", depth)
+		DebugPrint(n.Key, depth+1)
+		DebugPrint(n.Value, depth+1)
+		debugPrintClauses(n.Clauses, depth+1)
+	case *ast.GeneratorExpNode:
+		indentPrint("genexpr", depth)
+		DebugPrint(n.Element, depth+1)
+		debugPrintClauses(n.Clauses, depth+1)
+	case *ast.MatchNode:
+		indentPrint("match", depth)
+		DebugPrint(n.Subject, depth+1)
+		for _, c := range n.Cases {
+			indentPrint("case", depth+1)
+			DebugPrint(c.Pattern, depth+2)
+			if c.Guard != nil {
+				DebugPrint(c.Guard, depth+2)
+			}
+			DebugPrint(c.Body, depth+2)
+		}
+	case *ast.WithNode:
+		indentPrint("with", depth)
+		for _, item := range n.Items {
+			DebugPrint(item.Context, depth+1)
+			if item.Target != nil {
+				DebugPrint(item.Target, depth+1)
+			}
+		}
+		DebugPrint(n.Body, depth+1)
+	case *ast.TryNode:
+		indentPrint("try", depth)
+		DebugPrint(n.Body, depth+1)
+		for _, h := range n.Handlers {
+			indentPrint("except", depth+1)
+			if h.Type != nil {
+				DebugPrint(h.Type, depth+2)
+			}
+			DebugPrint(h.Body, depth+2)
+		}
+		if n.Else != nil {
+			DebugPrint(n.Else, depth+1)
+		}
+		if n.Finally != nil {
+			DebugPrint(n.Finally, depth+1)
+		}
+	case *ast.RaiseNode:
+		indentPrint("raise", depth)
+		if n.Exception != nil {
+			DebugPrint(n.Exception, depth+1)
+		}
+		if n.Cause != nil {
+			DebugPrint(n.Cause, depth+1)
+		}
+	case *ast.AssertNode:
+		indentPrint("assert", depth)
+		DebugPrint(n.Test, depth+1)
+		if n.Message != nil {
+			DebugPrint(n.Message, depth+1)
+		}
+	case *ast.DelNode:
+		indentPrint("del", depth)
+		DebugPrintAll(n.Targets, depth+1)
+	case *ast.ClassDefNode:
+		indentPrint("classdef", depth)
+		DebugPrintAll(n.Decorators, depth+1)
+		DebugPrintAll(n.Bases, depth+1)
+		DebugPrint(n.Body, depth+1)
+	case *ast.LambdaNode:
+		indentPrint("lambda", depth)
+		DebugPrintAll(n.Params, depth+1)
+		DebugPrint(n.Body, depth+1)
+	case *ast.NamedExprNode:
+		indentPrint("namedexpr", depth)
+		DebugPrint(n.Target, depth+1)
+		DebugPrint(n.Value, depth+1)
+	case *ast.ConditionalNode:
+		indentPrint("conditional", depth)
+		DebugPrint(n.Condition, depth+1)
+		DebugPrint(n.Body, depth+1)
+		DebugPrint(n.Else, depth+1)
+	case *ast.ImportNode:
+		indentPrint("import", depth)
+	case *ast.ImportFromNode:
+		indentPrint("importfrom", depth)
+	case *ast.YieldNode:
+		indentPrint("yield", depth)
+		if n.Value != nil {
+			DebugPrint(n.Value, depth+1)
+		}
 	default:
 		indentPrint("?", depth)
 	}
@@ -49,3 +178,446 @@ func DebugPrintAll(nodes []ast.Node, depth int) {
 		DebugPrint(n, depth)
 	}
 }
+
+func debugPrintClauses(clauses []*ast.ComprehensionNode, depth int) {
+	for _, c := range clauses {
+		indentPrint("comprehension", depth)
+		DebugPrint(c.Targets, depth+1)
+		DebugPrint(c.Values, depth+1)
+		DebugPrintAll(c.Ifs, depth+1)
+	}
+}
+
+type controlSignal struct {
+	keyword string
+}
+
+func (s *controlSignal) Error() string { return "'" + s.keyword + "' outside loop" }
+
+var (
+	errBreak    = &controlSignal{keyword: "break"}
+	errContinue = &controlSignal{keyword: "continue"}
+)
+
+func (in *Interpreter) Eval(node ast.Node, env *object.Environment) (object.Object, error) {
+	switch node := node.(type) {
+	case *ast.BlockNode:
+		return in.evalStatements(node.Statements, env)
+	case *ast.ExpressionsNode:
+		return in.evalExpressions(node, env)
+	case *ast.NumberNode:
+		return in.evalNumber(node)
+	case *ast.StringNode:
+		return &object.String{Value: node.Value}, nil
+	case *ast.IdentifierNode:
+		return in.evalIdentifier(node, env)
+	case *ast.TupleNode:
+		elements, err := in.evalElements(node.Elements, env)
+		if err != nil {
+			return nil, err
+		}
+		return &object.Tuple{Elements: elements}, nil
+	case *ast.ListNode:
+		elements, err := in.evalElements(node.Elements, env)
+		if err != nil {
+			return nil, err
+		}
+		return &object.List{Elements: elements}, nil
+	case *ast.DictNode:
+		return in.evalDict(node, env)
+	case *ast.SetNode:
+		return in.evalSet(node, env)
+	case *ast.ListCompNode:
+		return in.evalListComp(node, env)
+	case *ast.SetCompNode:
+		return in.evalSetComp(node, env)
+	case *ast.DictCompNode:
+		return in.evalDictComp(node, env)
+	case *ast.GeneratorExpNode:
+		return in.evalGeneratorExp(node, env)
+	case *ast.StarredNode, *ast.DoubleStarredNode:
+		return nil, object.NewError(object.SyntaxError, "can't use starred expression here")
+	case *ast.KeywordNode:
+		return nil, object.NewError(object.SyntaxError, "invalid syntax. Maybe you meant '==' instead of '='?")
+	case *ast.PrefixNode:
+		return in.evalPrefix(node, env)
+	case *ast.InfixNode:
+		return in.evalInfix(node, env)
+	case *ast.SliceNode:
+		return in.evalSubscript(node, env)
+	case *ast.SliceExprNode:
+		return in.evalSliceExpr(node, env)
+	case *ast.CallNode:
+		return in.evalCall(node, env)
+	case *ast.AssignmentNode:
+		return in.evalAssignment(node, env)
+	case *ast.IfNode:
+		return in.evalIf(node, env)
+	case *ast.WhileNode:
+		return in.evalWhile(node, env)
+	case *ast.ForNode:
+		return in.evalFor(node, env)
+	case *ast.WithNode:
+		return in.evalWith(node.Items, node.Body, env)
+	case *ast.TryNode:
+		return in.evalTry(node, env)
+	case *ast.RaiseNode:
+		return in.evalRaise(node, env)
+	case *ast.AssertNode:
+		return in.evalAssert(node, env)
+	case *ast.DelNode:
+		return in.evalDel(node, env)
+	case *ast.MatchNode:
+		return in.evalMatch(node, env)
+	case *ast.ControlNode:
+		return in.evalControl(node)
+	case *ast.FunctionDefNode:
+		return in.evalFunctionDef(node, env)
+	case *ast.ClassDefNode:
+		return in.evalClassDef(node, env)
+	case *ast.LambdaNode:
+		return in.evalLambda(node, env)
+	case *ast.ConditionalNode:
+		return in.evalConditional(node, env)
+	case *ast.NamedExprNode:
+		return in.evalNamedExpr(node, env)
+	case *ast.ReturnNode:
+		return in.evalReturn(node, env)
+	case *ast.YieldNode:
+		return in.evalYield(node, env)
+	case *ast.ImportNode:
+		return in.evalImport(node, env)
+	case *ast.ImportFromNode:
+		return in.evalImportFrom(node, env)
+	}
+
+	return nil, object.NewError(object.NotImplementedError, "evaluation of %T is not supported", node)
+}
+
+func (in *Interpreter) evalStatements(stmts []ast.Node, env *object.Environment) (object.Object, error) {
+	var result object.Object = object.NONE
+
+	for _, stmt := range stmts {
+		res, err := in.Eval(stmt, env)
+		if err != nil {
+			return nil, err
+		}
+		result = res
+	}
+
+	return result, nil
+}
+
+func (in *Interpreter) evalExpressions(node *ast.ExpressionsNode, env *object.Environment) (object.Object, error) {
+	elements, err := in.evalElements(node.Expressions, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(elements) == 1 {
+		return elements[0], nil
+	}
+	return &object.Tuple{Elements: elements}, nil
+}
+
+// evalElements evaluates the items of a display, expanding starred ones in place.
+func (in *Interpreter) evalElements(nodes []ast.Node, env *object.Environment) ([]object.Object, error) {
+	elements := make([]object.Object, 0, len(nodes))
+
+	for _, n := range nodes {
+		if starred, ok := n.(*ast.StarredNode); ok {
+			val, err := in.Eval(starred.Value, env)
+			if err != nil {
+				return nil, err
+			}
+
+			items, err := in.collect(val)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, items...)
+			continue
+		}
+
+		val, err := in.Eval(n, env)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, val)
+	}
+
+	return elements, nil
+}
+
+func (in *Interpreter) evalDict(node *ast.DictNode, env *object.Environment) (object.Object, error) {
+	dict := object.NewDict()
+	for i, keyNode := range node.Keys {
+		key, err := in.Eval(keyNode, env)
+		if err != nil {
+			return nil, err
+		}
+		val, err := in.Eval(node.Values[i], env)
+		if err != nil {
+			return nil, err
+		}
+		if err := in.dictSet(dict, key, val); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+func (in *Interpreter) evalSet(node *ast.SetNode, env *object.Environment) (object.Object, error) {
+	elements, err := in.evalElements(node.Elements, env)
+	if err != nil {
+		return nil, err
+	}
+
+	set := object.NewSet()
+	for _, elem := range elements {
+		if err := in.setAdd(set, elem); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func (in *Interpreter) evalNumber(node *ast.NumberNode) (object.Object, error) {
+	if value, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+		return &object.Integer{Value: value}, nil
+	} else if !strings.Contains(node.Value, ".") {
+		return nil, object.NewError(object.OverflowError, "integer literal %s is too large", node.Value)
+	}
+
+	value, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return nil, object.NewError(object.ValueError, "invalid number literal %q", node.Value)
+	}
+	return &object.Float{Value: value}, nil
+}
+
+func (in *Interpreter) evalIdentifier(node *ast.IdentifierNode, env *object.Environment) (object.Object, error) {
+	if val, ok := env.Get(node.Name); ok {
+		return val, nil
+	}
+
+	if env.Unbound(node.Name) {
+		return nil, unboundLocal(node.Name)
+	}
+
+	if builtin, ok := builtins[node.Name]; ok {
+		return builtin, nil
+	}
+
+	return nil, object.NewError(object.NameError, "name '%s' is not defined", node.Name)
+}
+
+func (in *Interpreter) evalIf(node *ast.IfNode, env *object.Environment) (object.Object, error) {
+	cond, err := in.Eval(node.Condition, env)
+	if err != nil {
+		return nil, err
+	}
+
+	truthy, err := in.isTruthy(cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if truthy {
+		return in.Eval(node.Body, env)
+	} else if node.Else != nil {
+		return in.Eval(node.Else, env)
+	}
+
+	return object.NONE, nil
+}
+
+func (in *Interpreter) evalConditional(node *ast.ConditionalNode, env *object.Environment) (object.Object, error) {
+	cond, err := in.Eval(node.Condition, env)
+	if err != nil {
+		return nil, err
+	}
+
+	truthy, err := in.isTruthy(cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if truthy {
+		return in.Eval(node.Body, env)
+	}
+	return in.Eval(node.Else, env)
+}
+
+func (in *Interpreter) evalWhile(node *ast.WhileNode, env *object.Environment) (object.Object, error) {
+	for {
+		cond, err := in.Eval(node.Condition, env)
+		if err != nil {
+			return nil, err
+		}
+
+		truthy, err := in.isTruthy(cond)
+		if err != nil {
+			return nil, err
+		}
+		if !truthy {
+			break
+		}
+
+		_, err = in.Eval(node.Body, env)
+		if err == errBreak {
+			return object.NONE, nil
+		} else if err != nil && err != errContinue {
+			return nil, err
+		}
+	}
+
+	if node.Else != nil {
+		return in.Eval(node.Else, env)
+	}
+
+	return object.NONE, nil
+}
+
+func (in *Interpreter) evalFor(node *ast.ForNode, env *object.Environment) (object.Object, error) {
+	iterable, err := in.Eval(node.Values, env)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := in.iterate(iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		item, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			break
+		}
+
+		if err := in.assign(node.Targets, item, env); err != nil {
+			return nil, err
+		}
+
+		_, err = in.Eval(node.Body, env)
+		if err == errBreak {
+			return object.NONE, nil
+		} else if err != nil && err != errContinue {
+			return nil, err
+		}
+	}
+
+	if node.Else != nil {
+		return in.Eval(node.Else, env)
+	}
+
+	return object.NONE, nil
+}
+
+// evalWith runs body inside the context managers of items, entering them
+// left to right as if the with statements were nested.
+func (in *Interpreter) evalWith(items []*ast.WithItemNode, body ast.Node, env *object.Environment) (object.Object, error) {
+	if len(items) == 0 {
+		return in.Eval(body, env)
+	}
+
+	mgr, err := in.Eval(items[0].Context, env)
+	if err != nil {
+		return nil, err
+	}
+
+	cm, err := in.contextManager(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := cm.Enter()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := func() (object.Object, error) {
+		if items[0].Target != nil {
+			if err := in.assign(items[0].Target, val, env); err != nil {
+				return nil, err
+			}
+		}
+		return in.evalWith(items[1:], body, env)
+	}()
+
+	// Only exceptions are passed to __exit__; break, continue and return
+	// leave the block normally.
+	exc, _ := err.(*object.Exception)
+	suppress, exitErr := cm.Exit(exc)
+	if exitErr != nil {
+		return nil, exitErr
+	}
+	if exc != nil && suppress {
+		return object.NONE, nil
+	}
+	return res, err
+}
+
+// contextManager returns mgr itself if it is implemented in Go, or else an
+// adapter calling its __enter__ and __exit__ methods.
+func (in *Interpreter) contextManager(mgr object.Object) (object.ContextManager, error) {
+	if cm, ok := mgr.(object.ContextManager); ok {
+		return cm, nil
+	}
+
+	cls := classOf(mgr)
+	enter, hasEnter := cls.LookupAttr("__enter__")
+	exit, hasExit := cls.LookupAttr("__exit__")
+	if !hasEnter || !hasExit {
+		return nil, object.NewError(object.TypeError, "'%s' object does not support the context manager protocol", mgr.Type())
+	}
+
+	cm := &methodContextManager{Object: mgr, in: in}
+	var err error
+	if cm.enter, err = in.bindAttr(mgr, enter); err != nil {
+		return nil, err
+	}
+	if cm.exit, err = in.bindAttr(mgr, exit); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+type methodContextManager struct {
+	object.Object
+	in    *Interpreter
+	enter object.Object
+	exit  object.Object
+}
+
+func (cm *methodContextManager) Enter() (object.Object, error) {
+	return cm.in.callObject(cm.enter, nil, nil)
+}
+
+func (cm *methodContextManager) Exit(exc *object.Exception) (bool, error) {
+	args := []object.Object{object.NONE, object.NONE, object.NONE}
+	if exc != nil {
+		args = []object.Object{exc.Class, exc, object.NONE}
+	}
+
+	res, err := cm.in.callObject(cm.exit, args, nil)
+	if err != nil {
+		return false, err
+	}
+	return cm.in.isTruthy(res)
+}
+
+func (in *Interpreter) evalControl(node *ast.ControlNode) (object.Object, error) {
+	switch node.Type {
+	case "break":
+		return nil, errBreak
+	case "continue":
+		return nil, errContinue
+	}
+
+	return object.NONE, nil
+}
@@ -0,0 +1,327 @@
+package evaluator
+
+import (
+	"snek/lexer"
+	"snek/object"
+	"snek/parser"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// run evaluates src as a __main__ module in a new interpreter, returning
+// what it printed.
+func run(t *testing.T, src string, opts ...Option) (string, error) {
+	t.Helper()
+	l := lexer.New(src)
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		t.Fatalf("lexing %q: %v", src, errs[0])
+	}
+	prog, err := parser.New(tokens).ParseFile()
+	if err != nil {
+		t.Fatalf("parsing %q: %v", src, err)
+	}
+
+	out := &strings.Builder{}
+	env := object.NewModule("__main__", "<test>", false).Env
+	_, err = New(append([]Option{WithStdout(out)}, opts...)...).Eval(prog, env)
+	return out.String(), err
+}
+
+type outputTest struct {
+	input    string
+	expected string
+}
+
+func testOutput(t *testing.T, tests []outputTest, opts ...Option) {
+	t.Helper()
+	for _, tt := range tests {
+		out, err := run(t, tt.input, opts...)
+		if err != nil {
+			t.Errorf("running %q failed: %v", tt.input, err)
+			continue
+		}
+		if out != tt.expected {
+			t.Errorf("running %q printed %q, want %q", tt.input, out, tt.expected)
+		}
+	}
+}
+
+const contextManagerClass = `
+class CM:
+    def __enter__(self):
+        print("enter")
+    def __exit__(self, t, v, tb):
+        print("exit")
+`
+
+func TestFinallyOnControlFlow(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"for i in range(3):\n    try:\n        if i == 1:\n            break\n    finally:\n        print(i)\n", "0\n1\n"},
+		{"for i in range(2):\n    try:\n        continue\n    finally:\n        print(i)\n    print('after')\n", "0\n1\n"},
+		{"def f():\n    try:\n        if True:\n            return 1\n    finally:\n        print('finally')\nprint(f())\n", "finally\n1\n"},
+		{"def f():\n    try:\n        return 1\n    finally:\n        return 2\nprint(f())\n", "2\n"},
+		{"while True:\n    try:\n        try:\n            break\n        finally:\n            print('inner')\n    finally:\n        print('outer')\n", "inner\nouter\n"},
+		{contextManagerClass + "for i in range(2):\n    with CM():\n        break\n", "enter\nexit\n"},
+		{contextManagerClass + "for i in range(2):\n    with CM():\n        continue\n", "enter\nexit\nenter\nexit\n"},
+		{contextManagerClass + "def f():\n    try:\n        with CM():\n            return 1\n    finally:\n        print('finally')\nprint(f())\n", "enter\nexit\nfinally\n1\n"},
+	})
+}
+
+func TestLoopElse(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"for i in range(2):\n    pass\nelse:\n    print('else')\n", "else\n"},
+		{"for i in range(2):\n    break\nelse:\n    print('else')\n", ""},
+		{"for i in range(2):\n    continue\nelse:\n    print('else')\n", "else\n"},
+		{"for i in []:\n    pass\nelse:\n    print('else')\n", "else\n"},
+		{"i = 0\nwhile i < 2:\n    i += 1\nelse:\n    print('else')\n", "else\n"},
+		{"while True:\n    break\nelse:\n    print('else')\n", ""},
+		{"for i in range(2):\n    try:\n        break\n    finally:\n        print('finally')\nelse:\n    print('else')\n", "finally\n"},
+		{"for i in range(2):\n    for j in range(2):\n        break\n    else:\n        print('inner')\nelse:\n    print('outer')\n", "outer\n"},
+	})
+}
+
+func TestRecursionLimit(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"def f():\n    return f()\ntry:\n    f()\nexcept RecursionError as e:\n    print(e)\n", "maximum recursion depth exceeded\n"},
+		{"def f(n):\n    return n if n == 0 else f(n - 1)\nprint(f(900))\n", "0\n"},
+		{"class A:\n    def __repr__(self):\n        return repr(self)\ntry:\n    repr(A())\nexcept RuntimeError:\n    print('caught')\n", "caught\n"},
+		{"def g(n):\n    yield n\n    yield from g(n + 1)\ntry:\n    for x in g(0):\n        pass\nexcept RecursionError:\n    print(x)\n", "999\n"},
+		{"def g():\n    yield 1\nl = [g() for i in range(2000)]\nfor x in l:\n    next(x)\ndef f(n):\n    return n if n == 0 else f(n - 1)\nprint(f(900))\n", "0\n"},
+	})
+	testOutput(t, []outputTest{
+		{"def f(n):\n    return n if n == 0 else f(n - 1)\nprint(f(49))\ntry:\n    f(50)\nexcept RecursionError:\n    print('limit')\n", "0\nlimit\n"},
+	}, WithRecursionLimit(50))
+}
+
+func TestConcurrentInterpreters(t *testing.T) {
+	src := "def f(n):\n    return n if n == 0 else f(n - 1)\na = [f(500)]\na.append(a)\nprint(a, {'a': a})\n"
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testOutput(t, []outputTest{{src, "[0, [...]] {'a': [0, [...]]}\n"}})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBuiltinClassesImmutable(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"try:\n    int.x = 1\nexcept TypeError as e:\n    print(e)\n", "cannot set 'x' attribute of immutable type 'int'\n"},
+		{"try:\n    del ValueError.__init__\nexcept TypeError as e:\n    print(e)\n", "cannot delete '__init__' attribute of immutable type 'ValueError'\n"},
+		{"class A:\n    pass\nA.x = 1\ndel A.x\nprint('ok')\n", "ok\n"},
+	})
+}
+
+func TestReprCycles(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"a = [1]\na.append(a)\nprint(a)\n", "[1, [...]]\n"},
+		{"d = {}\nd['d'] = d\nprint(repr(d))\n", "{'d': {...}}\n"},
+		{"a = []\nd = {'a': a}\na.append(d)\nprint(a, d)\n", "[{'a': [...]}] {'a': [{...}]}\n"},
+		{"t = ([],)\nt[0].append(t)\nprint(t)\n", "([([...],)],)\n"},
+		{"a = [1]\nprint([a, a])\n", "[[1], [1]]\n"},
+		{"class H:\n    def __repr__(self):\n        return 'H'\nprint({H()}, set())\n", "{H} set()\n"},
+	})
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	maxInt := "m = 9223372036854775807\nn = -m - 1\n"
+	tests := []outputTest{
+		{maxInt + "print(n, (-2) ** 63, 3 ** 39, 2 ** 31 * 2 ** 31, n % -1)\n", "-9223372036854775808 -9223372036854775808 4052555153018976267 4611686018427387904 0\n"},
+	}
+	for _, expr := range []string{"m + 1", "n - 1", "m * 2", "n * -1", "-1 * n", "n // -1", "2 ** 63", "3 ** 40", "-n", "abs(n)"} {
+		tests = append(tests, outputTest{
+			maxInt + "try:\n    " + expr + "\nexcept OverflowError:\n    print('overflow')\n",
+			"overflow\n",
+		})
+	}
+	testOutput(t, tests)
+}
+
+const hashedClass = `
+class H:
+    def __init__(self, v):
+        self.v = v
+    def __hash__(self):
+        return self.v % 2
+    def __eq__(self, other):
+        return isinstance(other, H) and self.v == other.v
+    def __repr__(self):
+        return "H(" + str(self.v) + ")"
+`
+
+func TestUserDefinedHash(t *testing.T) {
+	testOutput(t, []outputTest{
+		{hashedClass + "print(len({H(1), H(1)}), {H(1), H(3), H(1), H(2)})\n", "1 {H(1), H(3), H(2)}\n"},
+		{hashedClass + "d = {H(1): 'a'}\nd[H(1)] = 'b'\nd[H(3)] = 'c'\nprint(d, d[H(1)], H(3) in d, H(5) in d)\n", "{H(1): 'b', H(3): 'c'} b True False\n"},
+		{hashedClass + "d = {H(1): 'a', H(3): 'b'}\ndel d[H(1)]\nprint(d, H(1) in d, d.get(H(3)))\n", "{H(3): 'b'} False b\n"},
+		{hashedClass + "s = {H(1), H(3)}\ns.remove(H(1))\nprint(s, s == {H(3)}, {H(3)}.issubset(s))\n", "{H(3)} True True\n"},
+		{hashedClass + "print({H(1), H(2)}.intersection([H(2)]), {H(1), H(2)}.difference([H(2)]))\n", "{H(2)} {H(1)}\n"},
+		{hashedClass + "d = {H(1): 1}\nprint(dict(d) == d, d.copy() == {H(1): 1}, H(1) in d.keys(), (H(1), 1) in d.items())\n", "True True True True\n"},
+		{hashedClass + "d = {}\nd.setdefault(H(7), []).append(1)\nd.setdefault(H(7), []).append(2)\nprint(d)\n", "{H(7): [1, 2]}\n"},
+		{hashedClass + "class S(H):\n    pass\nprint(len({S(1), S(1)}))\n", "1\n"},
+		{"class P:\n    pass\np = P()\nprint(len({p, p, P()}))\n", "2\n"},
+		{"class E:\n    def __eq__(self, other):\n        return True\ntry:\n    {E()}\nexcept TypeError as e:\n    print(e)\n", "unhashable type: 'E'\n"},
+		{"class N:\n    __hash__ = None\ntry:\n    {N(): 1}\nexcept TypeError as e:\n    print(e)\n", "unhashable type: 'N'\n"},
+		{"class B:\n    def __hash__(self):\n        return 'x'\ntry:\n    {B()}\nexcept TypeError as e:\n    print(e)\n", "__hash__ method should return an integer\n"},
+		{hashedClass + "d = {(H(1), 2): 't'}\nprint((H(1), 2) in d, (H(1), 3) in d, d[(H(1), 2)], len({(H(1), (H(2),)), (H(1), (H(2),))}))\n", "True False t 1\n"},
+		{"class E:\n    def __eq__(self, other):\n        return True\ntry:\n    {(1, E())}\nexcept TypeError as e:\n    print(e)\n", "unhashable type: 'E'\n"},
+	})
+}
+
+func TestAssert(t *testing.T) {
+	src := "try:\n    assert 1 == 2, 'nope'\nexcept AssertionError as e:\n    print('failed', e)\nassert True\nprint('done')\n"
+	testOutput(t, []outputTest{
+		{src, "failed nope\ndone\n"},
+		{"try:\n    assert []\nexcept AssertionError as e:\n    print(e.args)\n", "()\n"},
+	})
+	testOutput(t, []outputTest{
+		{src, "done\n"},
+		{"def f():\n    print('evaluated')\nassert f()\nprint('done')\n", "done\n"},
+	}, WithOptimize())
+}
+
+func TestDel(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"x = 1\ndel x\ntry:\n    x\nexcept NameError as e:\n    print(e)\n", "name 'x' is not defined\n"},
+		{"l = [0, 1, 2, 3, 4]\ndel l[0]\ndel l[1:3]\nprint(l)\n", "[1, 4]\n"},
+		{"l = list(range(6))\ndel l[::2], l[-1]\nprint(l)\n", "[1, 3]\n"},
+		{"d = {'a': 1, 'b': 2}\ndel d['a']\nprint(d)\ntry:\n    del d['a']\nexcept KeyError as e:\n    print(repr(e))\n", "{'b': 2}\nKeyError('a')\n"},
+		{"class A:\n    pass\na = A()\na.y = 1\ndel a.y\ntry:\n    a.y\nexcept AttributeError as e:\n    print(e)\n", "'A' object has no attribute 'y'\n"},
+		{"x, y = 1, 2\ndel (x, y)\ntry:\n    y\nexcept NameError as e:\n    print(e)\n", "name 'y' is not defined\n"},
+	})
+}
+
+func TestUnboundLocal(t *testing.T) {
+	const msg = "cannot access local variable 'x' where it is not associated with a value\n"
+	testOutput(t, []outputTest{
+		{"x = 1\ndef g():\n    x += 1\n    return x\ntry:\n    g()\nexcept UnboundLocalError as e:\n    print(e)\n", msg},
+		{"x = 1\ndef g():\n    print(x)\n    x = 2\ntry:\n    g()\nexcept NameError as e:\n    print(type(e).__name__)\n", "UnboundLocalError\n"},
+		{"def g(x):\n    del x\n    del x\ntry:\n    g(1)\nexcept UnboundLocalError as e:\n    print(e)\n", msg},
+		{"def g(v):\n    match v:\n        case [x]:\n            pass\n    return x\nprint(g([1]))\ntry:\n    g(2)\nexcept UnboundLocalError as e:\n    print(e)\n", "1\n" + msg},
+		{"def g():\n    print(len)\n    for len in []:\n        pass\ntry:\n    g()\nexcept UnboundLocalError as e:\n    print(e)\n", "cannot access local variable 'len' where it is not associated with a value\n"},
+		{"x = 1\ndef g():\n    y = x\n    return [x for x in range(2)], (lambda: x + y)()\nprint(g())\n", "([0, 1], 2)\n"},
+		{"def g():\n    [(x := i) for i in range(3)]\n    return x\nprint(g())\n", "2\n"},
+	})
+}
+
+func TestRaise(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"try:\n    raise ValueError('a') from KeyError('b')\nexcept ValueError as e:\n    print(repr(e.__cause__), e.__suppress_context__)\n", "KeyError('b') True\n"},
+		{"try:\n    try:\n        raise KeyError('x')\n    except KeyError:\n        raise ValueError('y') from None\nexcept ValueError as e:\n    print(e.__cause__, repr(e.__context__), e.__suppress_context__)\n", "None KeyError('x') True\n"},
+		{"try:\n    raise ValueError from 1\nexcept TypeError as e:\n    print(e)\n", "exception causes must derive from BaseException\n"},
+		{"try:\n    raise\nexcept RuntimeError as e:\n    print(e)\n", "No active exception to reraise\n"},
+		{"try:\n    try:\n        raise ValueError('v')\n    except ValueError:\n        raise\nexcept ValueError as e:\n    print('caught', e)\n", "caught v\n"},
+		{"def reraise():\n    raise\ntry:\n    try:\n        raise ValueError('v')\n    except ValueError:\n        reraise()\nexcept ValueError as e:\n    print('caught', e)\ntry:\n    reraise()\nexcept RuntimeError as e:\n    print(e)\n", "caught v\nNo active exception to reraise\n"},
+		{"def g():\n    try:\n        raise KeyError('k')\n    except KeyError:\n        yield 1\n        raise\nit = g()\nnext(it)\ntry:\n    raise ValueError('outer')\nexcept ValueError:\n    try:\n        next(it)\n    except KeyError as e:\n        print(repr(e))\n", "KeyError('k')\n"},
+		{"def g():\n    yield 1\n    raise\nit = g()\ntry:\n    raise TypeError('t')\nexcept TypeError:\n    next(it)\n    try:\n        next(it)\n    except TypeError as e:\n        print(e)\n", "t\n"},
+		{"def g():\n    try:\n        raise KeyError('k')\n    except KeyError:\n        yield 1\nit = g()\nnext(it)\ntry:\n    raise\nexcept RuntimeError as e:\n    print(e)\n", "No active exception to reraise\n"},
+	})
+}
+
+const pointClass = `class Point:
+    __match_args__ = ('x', 'y')
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+`
+
+func TestMatch(t *testing.T) {
+	describe := "def f(x):\n    match x:\n" +
+		"        case 0 | 1:\n            return 'small'\n" +
+		"        case -1:\n            return 'minus one'\n" +
+		"        case 'a' 'b':\n            return 'ab'\n" +
+		"        case None:\n            return 'none'\n" +
+		"        case [] | ():\n            return 'empty'\n" +
+		"        case [1, *rest]:\n            return 'one then ' + repr(rest)\n" +
+		"        case (a, b):\n            return 'pair ' + repr(a) + ' ' + repr(b)\n" +
+		"        case {'k': v, **others}:\n            return 'k=' + repr(v) + ' ' + repr(others)\n" +
+		"        case {}:\n            return 'mapping'\n" +
+		"        case str() as s if len(s) > 3:\n            return 'long ' + s\n" +
+		"        case int(n) if n > 100:\n            return 'big'\n" +
+		"        case _:\n            return 'other'\n"
+	testOutput(t, []outputTest{
+		{describe + "print(f(0), f(1), f(True), f(-1), f('ab'), f(None))\n", "small small small minus one ab none\n"},
+		{describe + "print(f([]), f(()), f([1, 2, 3]), f((4, 5)), f([1.5]))\n", "empty empty one then [2, 3] pair 4 5 other\n"},
+		{describe + "print(f({'k': 1, 'j': 2}), f({'z': 1}))\n", "k=1 {'j': 2} mapping\n"},
+		{describe + "print(f('hello'), f('hi'), f(500), f(50), f('abc'))\n", "long hello other big other other\n"},
+		{"match (1, 2):\n    case (1, x) | (x, 1):\n        print('x is', x)\n", "x is 2\n"},
+		{"match [1, [2, 3]]:\n    case [a, [b, *c]] as whole:\n        print(a, b, c, whole)\n", "1 2 [3] [1, [2, 3]]\n"},
+		{"match 'abc':\n    case [*_]:\n        print('sequence')\n    case str(s):\n        print('str', s)\n", "str abc\n"},
+		{"match 5:\n    case 1:\n        print('one')\nprint('done')\n", "done\n"},
+		{"class Color:\n    RED = 1\n    GREEN = 2\nmatch 2:\n    case Color.RED:\n        print('red')\n    case Color.GREEN:\n        print('green')\n", "green\n"},
+		{"match {'a': 1}:\n    case {'a': 1, **rest}:\n        print(rest)\n", "{}\n"},
+		{"match 1.0:\n    case 1:\n        print('equal')\n", "equal\n"},
+		{"match 1:\n    case True:\n        print('true')\n    case _:\n        print('not the same object')\n", "not the same object\n"},
+	})
+}
+
+func TestMatchClassPatterns(t *testing.T) {
+	testOutput(t, []outputTest{
+		{pointClass + "match Point(1, 2):\n    case Point(0, y):\n        print('on y axis', y)\n    case Point(x, y=2):\n        print('x', x)\n", "x 1\n"},
+		{pointClass + "match Point(0, 5):\n    case Point(x=0, y=yy):\n        print('yy', yy)\n", "yy 5\n"},
+		{pointClass + "match Point(0, 5):\n    case Point(z=1):\n        print('z')\n    case Point():\n        print('point')\n", "point\n"},
+		{pointClass + "try:\n    match Point(1, 2):\n        case Point(1, 2, 3):\n            pass\nexcept TypeError as e:\n    print(e)\n", "Point() accepts 2 positional sub-patterns (3 given)\n"},
+		{"class A:\n    pass\ntry:\n    match A():\n        case A(1):\n            pass\nexcept TypeError as e:\n    print(e)\n", "A() accepts 0 positional sub-patterns (1 given)\n"},
+		{"match 3:\n    case int(x) | str(x):\n        print(x)\n", "3\n"},
+		{"match [1, 2]:\n    case [x, y] if x > y:\n        print('desc')\n    case [x, y]:\n        print('asc', x, y)\n", "asc 1 2\n"},
+		{"match 1:\n    case x if (y := x + 1) > 5:\n        pass\n    case _:\n        print(x, y)\n", "1 2\n"},
+	})
+}
+
+func TestMatchSoftKeywords(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"match = 1\ncase = 2\nprint(match + case)\n", "3\n"},
+		{"def match(x):\n    return x\nprint(match(1), match([2]))\n", "1 [2]\n"},
+		{"match = [1]\nmatch[0] = 2\nprint(match)\n", "[2]\n"},
+		{"_ = 5\nmatch _:\n    case _:\n        print(_)\n", "5\n"},
+	})
+}
+
+const catcherGenerator = `def catcher():
+    while True:
+        try:
+            yield 'ok'
+        except ValueError as e:
+            yield 'caught ' + str(e)
+`
+
+func TestGenerators(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"def echo():\n    x = yield 1\n    while True:\n        x = yield x * 2\ng = echo()\nprint(next(g), g.send(5), g.send(10))\n", "1 10 20\n"},
+		{"def echo():\n    yield\ntry:\n    echo().send(1)\nexcept TypeError as e:\n    print(e)\n", "can't send non-None value to a just-started generator\n"},
+		{"def ret():\n    yield 1\n    return 'value'\ng = ret()\nnext(g)\ntry:\n    next(g)\nexcept StopIteration as e:\n    print(e.value)\n", "value\n"},
+		{"def counter():\n    i = 0\n    while True:\n        i += 1\n        yield i\nc = counter()\nprint([next(c) for _ in range(3)], list(x * 2 for x in range(3)))\n", "[1, 2, 3] [0, 2, 4]\n"},
+	})
+}
+
+func TestGeneratorThrow(t *testing.T) {
+	testOutput(t, []outputTest{
+		{catcherGenerator + "g = catcher()\nnext(g)\nprint(g.throw(ValueError('v')))\nprint(next(g))\n", "caught v\nok\n"},
+		{catcherGenerator + "g = catcher()\nnext(g)\ntry:\n    g.throw(KeyError('k'))\nexcept KeyError as e:\n    print('propagated', repr(e))\ntry:\n    next(g)\nexcept StopIteration:\n    print('finished')\n", "propagated KeyError('k')\nfinished\n"},
+		{catcherGenerator + "g = catcher()\ntry:\n    g.throw(ValueError)\nexcept ValueError:\n    print('not started')\n", "not started\n"},
+		{catcherGenerator + "def delegating():\n    yield from catcher()\ng = delegating()\nnext(g)\nprint(g.throw(ValueError('through')))\n", "caught through\n"},
+	})
+}
+
+func TestGeneratorClose(t *testing.T) {
+	closer := "def closer():\n    try:\n        yield 1\n    finally:\n        print('cleanup')\n"
+	testOutput(t, []outputTest{
+		{closer + "g = closer()\nnext(g)\ng.close()\ng.close()\nprint('closed twice')\n", "cleanup\nclosed twice\n"},
+		{closer + "g = closer()\ng.close()\nprint('never started')\n", "never started\n"},
+		{closer + "g = closer()\nnext(g)\ng.close()\ntry:\n    next(g)\nexcept StopIteration:\n    print('exhausted')\n", "cleanup\nexhausted\n"},
+		{"def stubborn():\n    try:\n        yield 1\n    except GeneratorExit:\n        yield 2\ng = stubborn()\nnext(g)\ntry:\n    g.close()\nexcept RuntimeError as e:\n    print(e)\n", "generator ignored GeneratorExit\n"},
+		{"def quiet():\n    try:\n        yield 1\n    except GeneratorExit:\n        print('exiting')\n        raise\ng = quiet()\nnext(g)\ng.close()\n", "exiting\n"},
+	})
+}
+
+func TestYieldFrom(t *testing.T) {
+	testOutput(t, []outputTest{
+		{"def inner():\n    x = yield 1\n    return x + 1\ndef outer():\n    r = yield from inner()\n    print('inner returned', r)\n    yield r\ng = outer()\nnext(g)\nprint(g.send(41))\n", "inner returned 42\n42\n"},
+		{"def outer():\n    r = yield from [1, 2]\n    yield r\nprint(list(outer()))\n", "[1, 2, None]\n"},
+		{"def inner():\n    return 'direct'\n    yield\ndef outer():\n    print((yield from inner()))\nlist(outer())\n", "direct\n"},
+	})
+}
@@ -0,0 +1,196 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"snek/object"
+)
+
+func (in *Interpreter) evalAssert(node *ast.AssertNode, env *object.Environment) (object.Object, error) {
+	if in.optimize {
+		return object.NONE, nil
+	}
+
+	test, err := in.Eval(node.Test, env)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := in.isTruthy(test)
+	if err != nil || ok {
+		return object.NONE, err
+	}
+
+	exc := &object.Exception{Class: object.AssertionError}
+	if node.Message != nil {
+		msg, err := in.Eval(node.Message, env)
+		if err != nil {
+			return nil, err
+		}
+		exc.Args = []object.Object{msg}
+	}
+	return nil, exc
+}
+
+func (in *Interpreter) evalRaise(node *ast.RaiseNode, env *object.Environment) (object.Object, error) {
+	if node.Exception == nil {
+		if exc := in.handling; exc != nil {
+			return nil, exc
+		}
+		return nil, object.NewError(object.RuntimeError, "No active exception to reraise")
+	}
+
+	val, err := in.Eval(node.Exception, env)
+	if err != nil {
+		return nil, err
+	}
+	exc, err := in.toException(val, "exceptions must derive from BaseException")
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Cause != nil {
+		val, err := in.Eval(node.Cause, env)
+		if err != nil {
+			return nil, err
+		}
+		exc.Cause = nil
+		if val != object.NONE {
+			if exc.Cause, err = in.toException(val, "exception causes must derive from BaseException"); err != nil {
+				return nil, err
+			}
+		}
+		exc.SuppressContext = true
+	}
+
+	return nil, exc
+}
+
+// toException returns val if it is an exception, or an instance of it if it
+// is an exception class.
+func (in *Interpreter) toException(val object.Object, msg string) (*object.Exception, error) {
+	switch val := val.(type) {
+	case *object.Exception:
+		return val, nil
+	case *object.Class:
+		if !val.IsSubclass(object.BaseExceptionClass) {
+			break
+		}
+		res, err := in.callObject(val, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if exc, ok := res.(*object.Exception); ok {
+			return exc, nil
+		}
+		return nil, object.NewError(object.TypeError, "calling %s should have returned an instance of BaseException, not %s", val.Inspect(), res.Type())
+	}
+	return nil, object.NewError(object.TypeError, "%s", msg)
+}
+
+// evalTry runs a try statement. The finally block runs however the rest
+// ends, and an exception, break, continue or return from it takes over.
+func (in *Interpreter) evalTry(node *ast.TryNode, env *object.Environment) (object.Object, error) {
+	res, err := in.evalTryExcept(node, env)
+	if node.Finally == nil {
+		return res, err
+	}
+
+	exc, _ := err.(*object.Exception)
+	prev := in.handling
+	if exc != nil {
+		in.handling = exc
+	}
+	_, finallyErr := in.Eval(node.Finally, env)
+	in.handling = prev
+
+	if finallyErr != nil {
+		chainContext(finallyErr, exc)
+		return nil, finallyErr
+	}
+	return res, err
+}
+
+func (in *Interpreter) evalTryExcept(node *ast.TryNode, env *object.Environment) (object.Object, error) {
+	res, err := in.Eval(node.Body, env)
+	exc, ok := err.(*object.Exception)
+	if !ok {
+		if err == nil && node.Else != nil {
+			return in.Eval(node.Else, env)
+		}
+		return res, err
+	}
+
+	for _, handler := range node.Handlers {
+		matched, err := in.exceptionMatches(exc, handler.Type, env)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return in.evalHandler(handler, exc, env)
+		}
+	}
+	return nil, exc
+}
+
+// exceptionMatches reports whether exc is caught by an except clause for
+// typ, which may be a class or a tuple of classes.
+func (in *Interpreter) exceptionMatches(exc *object.Exception, typ ast.Node, env *object.Environment) (bool, error) {
+	if typ == nil {
+		return true, nil
+	}
+
+	val, err := in.Eval(typ, env)
+	if err != nil {
+		return false, err
+	}
+
+	classes := []object.Object{val}
+	if t, ok := val.(*object.Tuple); ok {
+		classes = t.Elements
+	}
+	for _, c := range classes {
+		cls, ok := c.(*object.Class)
+		if !ok || !cls.IsSubclass(object.BaseExceptionClass) {
+			return false, object.NewError(object.TypeError, "catching classes that do not inherit from BaseException is not allowed")
+		}
+		if exc.Class.IsSubclass(cls) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (in *Interpreter) evalHandler(handler *ast.ExceptHandlerNode, exc *object.Exception, env *object.Environment) (object.Object, error) {
+	if handler.Name != nil {
+		if err := in.assign(handler.Name, exc, env); err != nil {
+			return nil, err
+		}
+	}
+
+	prev := in.handling
+	in.handling = exc
+	_, err := in.Eval(handler.Body, env)
+	in.handling = prev
+
+	// Like Python, the name is unbound when the clause ends.
+	if name, ok := handler.Name.(*ast.IdentifierNode); ok {
+		env.Delete(name.Name)
+	}
+
+	chainContext(err, exc)
+	return object.NONE, err
+}
+
+// chainContext records handled as the context of an exception raised while
+// it was being handled.
+func chainContext(err error, handled *object.Exception) {
+	exc, ok := err.(*object.Exception)
+	if !ok || handled == nil || exc == handled || exc.Context != nil {
+		return
+	}
+	for c := handled; c != nil; c = c.Context {
+		if c == exc {
+			return
+		}
+	}
+	exc.Context = handled
+}
@@ -0,0 +1,446 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"snek/ast"
+	"snek/object"
+	"strings"
+)
+
+type returnSignal struct {
+	value object.Object
+}
+
+func (s *returnSignal) Error() string { return "'return' outside function" }
+
+func (in *Interpreter) evalFunctionDef(node *ast.FunctionDefNode, env *object.Environment) (object.Object, error) {
+	decorators, err := in.evalElements(node.Decorators, env)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, err := in.newFunction(node.Name.String(), node.Params, node.Body, node.IsGenerator, in.localNames(node, node.Params, node.Body), env)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := in.applyDecorators(decorators, fn)
+	if err != nil {
+		return nil, err
+	}
+	env.Set(node.Name.String(), res)
+	return object.NONE, nil
+}
+
+// applyDecorators calls each decorator on the result of the one below it.
+func (in *Interpreter) applyDecorators(decorators []object.Object, obj object.Object) (object.Object, error) {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		res, err := in.callObject(decorators[i], []object.Object{obj}, nil)
+		if err != nil {
+			return nil, err
+		}
+		obj = res
+	}
+	return obj, nil
+}
+
+// evalLambda builds a function whose body returns the lambda's expression.
+func (in *Interpreter) evalLambda(node *ast.LambdaNode, env *object.Environment) (object.Object, error) {
+	return in.newFunction("<lambda>", node.Params, &ast.ReturnNode{Value: node.Body}, node.IsGenerator, in.localNames(node, node.Params, node.Body), env)
+}
+
+// newFunction creates a closure over env, evaluating default values now.
+func (in *Interpreter) newFunction(name string, params []ast.Node, body ast.Node, isGenerator bool, locals map[string]bool, env *object.Environment) (*object.Function, error) {
+	fn := &object.Function{
+		Name:        name,
+		Body:        body,
+		Env:         env.FunctionScope(),
+		IsGenerator: isGenerator,
+		Locals:      locals,
+		Attrs:       map[string]object.Object{},
+	}
+	if doc := docstring(body); doc != nil {
+		fn.Attrs["__doc__"] = doc
+	}
+
+	keywordOnly := false
+	for _, p := range params {
+		param := p.(*ast.ParamNode)
+		switch param.Prefix {
+		case "*":
+			if param.Name != nil {
+				fn.VarArgs = param.Name.String()
+			}
+			keywordOnly = true
+			continue
+		case "**":
+			fn.KwArgs = param.Name.String()
+			continue
+		}
+
+		fn.Params = append(fn.Params, param.Name.String())
+		if keywordOnly {
+			fn.KwOnly++
+		}
+
+		var def object.Object
+		if param.DefaultValue != nil {
+			val, err := in.Eval(param.DefaultValue, env)
+			if err != nil {
+				return nil, err
+			}
+			def = val
+		}
+		fn.Defaults = append(fn.Defaults, def)
+	}
+
+	return fn, nil
+}
+
+func (in *Interpreter) evalReturn(node *ast.ReturnNode, env *object.Environment) (object.Object, error) {
+	var val object.Object = object.NONE
+	if node.Value != nil {
+		res, err := in.Eval(node.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		val = res
+	}
+
+	return nil, &returnSignal{value: val}
+}
+
+func (in *Interpreter) evalCall(node *ast.CallNode, env *object.Environment) (object.Object, error) {
+	fn, err := in.Eval(node.Function, env)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []object.Object{}
+	var kwargs []object.Keyword
+
+	for _, arg := range node.Args {
+		switch arg := arg.(type) {
+		case *ast.KeywordNode:
+			val, err := in.Eval(arg.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			kwargs = append(kwargs, object.Keyword{Name: arg.Name.String(), Value: val})
+		case *ast.StarredNode:
+			val, err := in.Eval(arg.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			items, err := in.collect(val)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, items...)
+		case *ast.DoubleStarredNode:
+			val, err := in.Eval(arg.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			dict, ok := val.(*object.Dict)
+			if !ok {
+				return nil, object.NewError(object.TypeError, "argument after ** must be a mapping, not %s", val.Type())
+			}
+			for i := range dict.Len() {
+				pair := dict.PairAt(i)
+				key, ok := pair.Key.(*object.String)
+				if !ok {
+					return nil, object.NewError(object.TypeError, "keywords must be strings")
+				}
+				kwargs = append(kwargs, object.Keyword{Name: key.Value, Value: pair.Value})
+			}
+		default:
+			val, err := in.Eval(arg, env)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, val)
+		}
+	}
+
+	return in.callObject(fn, args, kwargs)
+}
+
+func (in *Interpreter) callObject(fn object.Object, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	switch fn := fn.(type) {
+	case *object.Function:
+		env, err := bindArguments(fn, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		if fn.IsGenerator {
+			return object.NewGenerator(fn.Name, func(yield object.YieldFunc) (object.Object, error) {
+				// While suspended, the generator's handled exception gives way
+				// to that of whoever resumes it.
+				caller := in.handling
+				env.SetYield(func(value object.Object) (object.Object, error) {
+					in.depth--
+					own := in.handling
+					in.handling = caller
+					res, err := yield(value)
+					if own == caller {
+						own = in.handling
+					}
+					caller, in.handling = in.handling, own
+					in.depth++
+					return res, err
+				})
+				res, err := in.evalBody(fn, env)
+				in.handling = caller
+				return res, err
+			}), nil
+		}
+		return in.evalBody(fn, env)
+	case *object.Builtin:
+		return fn.Fn(in, args, kwargs)
+	case *object.BoundMethod:
+		return in.callObject(fn.Function, append([]object.Object{fn.Self}, args...), kwargs)
+	case *object.StaticMethod:
+		return in.callObject(fn.Function, args, kwargs)
+	case *object.Class:
+		if fn.Constructor != nil {
+			return fn.Constructor(in, args, kwargs)
+		}
+		if typeClasses[object.ObjectType(fn.Name)] == fn {
+			return nil, object.NewError(object.TypeError, "cannot create '%s' instances", fn.Name)
+		}
+		return in.instantiate(fn, args, kwargs)
+	case *object.Instance:
+		if call, ok := fn.Class.LookupAttr("__call__"); ok {
+			method, err := in.bindAttr(fn, call)
+			if err != nil {
+				return nil, err
+			}
+			return in.callObject(method, args, kwargs)
+		}
+	}
+
+	return nil, object.NewError(object.TypeError, "'%s' object is not callable", fn.Type())
+}
+
+func (in *Interpreter) evalBody(fn *object.Function, env *object.Environment) (object.Object, error) {
+	if in.depth >= in.recursionLimit {
+		return nil, object.NewError(object.RecursionError, "maximum recursion depth exceeded")
+	}
+	in.depth++
+	_, err := in.Eval(fn.Body, env)
+	in.depth--
+	if ret, ok := err.(*returnSignal); ok {
+		return ret.value, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return object.NONE, nil
+}
+
+func (in *Interpreter) evalYield(node *ast.YieldNode, env *object.Environment) (object.Object, error) {
+	yield := env.Yield()
+	if yield == nil {
+		return nil, object.NewError(object.SyntaxError, "'yield' outside function")
+	}
+
+	var val object.Object = object.NONE
+	if node.Value != nil {
+		res, err := in.Eval(node.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		val = res
+	}
+
+	if node.From {
+		return in.yieldFrom(val, yield)
+	}
+	return yield(val)
+}
+
+// yieldFrom delegates to an inner iterable until it is exhausted. Values sent
+// and exceptions thrown into the outer generator are passed through to inner
+// generators; the result is the inner generator's return value.
+func (in *Interpreter) yieldFrom(iterable object.Object, yield object.YieldFunc) (object.Object, error) {
+	gen, ok := iterable.(*object.Generator)
+	if !ok {
+		it, err := in.iterate(iterable)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			item, err := it.Next()
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				return object.NONE, nil
+			}
+			if _, err := yield(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var sent object.Object = object.NONE
+	var thrown error
+	for {
+		item, err := gen.Resume(sent, thrown)
+		if err != nil {
+			if exc, ok := err.(*object.Exception); ok && exc.Class == object.StopIteration && gen.Finished() {
+				return stopValue(exc), nil
+			}
+			return nil, err
+		}
+
+		sent, thrown = yield(item)
+		if exc, ok := thrown.(*object.Exception); ok && exc.Class.IsSubclass(object.GeneratorExit) {
+			if err := gen.Close(); err != nil {
+				return nil, err
+			}
+			return nil, thrown
+		}
+	}
+}
+
+func stopValue(exc *object.Exception) object.Object {
+	if len(exc.Args) > 0 {
+		return exc.Args[0]
+	}
+	return object.NONE
+}
+
+func bindArguments(fn *object.Function, args []object.Object, kwargs []object.Keyword) (*object.Environment, error) {
+	positional := len(fn.Params) - fn.KwOnly
+	var extra []object.Object
+	if len(args) > positional {
+		if fn.VarArgs == "" {
+			required := slices.IndexFunc(fn.Defaults[:positional], func(def object.Object) bool { return def != nil })
+			return nil, object.NewError(object.TypeError, "%s() takes %s but %d %s given",
+				fn.Name, countParams(required, positional), len(args), plural(len(args), "was", "were"))
+		}
+		extra = args[positional:]
+		args = args[:positional]
+	}
+
+	bound := make([]object.Object, len(fn.Params))
+	copy(bound, args)
+
+	var extraKwargs *object.Dict
+	if fn.KwArgs != "" {
+		extraKwargs = object.NewDict()
+	}
+
+	for _, kw := range kwargs {
+		i := slices.Index(fn.Params, kw.Name)
+		if i < 0 {
+			if extraKwargs == nil {
+				return nil, object.NewError(object.TypeError, "%s() got an unexpected keyword argument '%s'", fn.Name, kw.Name)
+			}
+			key := &object.String{Value: kw.Name}
+			hash, _ := object.Hash(key)
+			if _, ok := extraKwargs.Get(hash); ok {
+				return nil, object.NewError(object.TypeError, "%s() got multiple values for keyword argument '%s'", fn.Name, kw.Name)
+			}
+			extraKwargs.Set(hash, key, kw.Value)
+			continue
+		} else if bound[i] != nil {
+			return nil, object.NewError(object.TypeError, "%s() got multiple values for argument '%s'", fn.Name, kw.Name)
+		}
+		bound[i] = kw.Value
+	}
+
+	missing, missingKeywords := []string{}, []string{}
+	for i, val := range bound {
+		if val == nil {
+			bound[i] = fn.Defaults[i]
+		}
+		if bound[i] == nil && i < positional {
+			missing = append(missing, "'"+fn.Params[i]+"'")
+		} else if bound[i] == nil {
+			missingKeywords = append(missingKeywords, "'"+fn.Params[i]+"'")
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, object.NewError(object.TypeError, "%s() missing %d required positional %s: %s",
+			fn.Name, len(missing), plural(len(missing), "argument", "arguments"), joinNames(missing))
+	}
+	if len(missingKeywords) > 0 {
+		return nil, object.NewError(object.TypeError, "%s() missing %d required keyword-only %s: %s",
+			fn.Name, len(missingKeywords), plural(len(missingKeywords), "argument", "arguments"), joinNames(missingKeywords))
+	}
+
+	env := object.NewFunctionEnvironment(fn.Env, fn.Locals)
+	for i, name := range fn.Params {
+		env.Set(name, bound[i])
+	}
+	if fn.VarArgs != "" {
+		env.Set(fn.VarArgs, &object.Tuple{Elements: append([]object.Object{}, extra...)})
+	}
+	if extraKwargs != nil {
+		env.Set(fn.KwArgs, extraKwargs)
+	}
+	return env, nil
+}
+
+func countParams(required, total int) string {
+	if required < 0 {
+		required = total
+	}
+	if required == total {
+		return fmt.Sprintf("%d positional %s", total, plural(total, "argument", "arguments"))
+	}
+	return fmt.Sprintf("from %d to %d positional arguments", required, total)
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// joinNames formats a list the way Python's argument errors do: 'a', 'b' and 'c'.
+func joinNames(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+// bindArgs maps the arguments of a built-in call onto its named parameters.
+// The first required parameters are mandatory; absent optional ones are nil.
+func bindArgs(name string, args []object.Object, kwargs []object.Keyword, required int, params ...string) ([]object.Object, error) {
+	if len(args) > len(params) {
+		return nil, object.NewError(object.TypeError, "%s() takes at most %d %s (%d given)",
+			name, len(params), plural(len(params), "argument", "arguments"), len(args))
+	}
+
+	bound := make([]object.Object, len(params))
+	copy(bound, args)
+
+	for _, kw := range kwargs {
+		i := slices.Index(params, kw.Name)
+		if i < 0 {
+			return nil, object.NewError(object.TypeError, "%s() got an unexpected keyword argument '%s'", name, kw.Name)
+		} else if bound[i] != nil {
+			return nil, object.NewError(object.TypeError, "%s() got multiple values for argument '%s'", name, kw.Name)
+		}
+		bound[i] = kw.Value
+	}
+
+	for i := range required {
+		if bound[i] == nil {
+			return nil, object.NewError(object.TypeError, "%s() missing required argument '%s' (pos %d)", name, params[i], i+1)
+		}
+	}
+
+	return bound, nil
+}
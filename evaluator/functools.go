@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"maps"
+	"snek/object"
+)
+
+// wrapperAssignments are the attributes functools.wraps copies to the wrapper.
+var wrapperAssignments = []string{"__module__", "__name__", "__qualname__", "__doc__"}
+
+func init() {
+	RegisterModule("functools", func(mod *object.Module) error {
+		mod.Env.Set("update_wrapper", &object.Builtin{Name: "update_wrapper", Fn: builtin((*Interpreter).builtinUpdateWrapper)})
+		mod.Env.Set("wraps", &object.Builtin{Name: "wraps", Fn: builtin((*Interpreter).builtinWraps)})
+		return nil
+	})
+}
+
+func (in *Interpreter) builtinUpdateWrapper(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("update_wrapper", args, kwargs, 2, "wrapper", "wrapped")
+	if err != nil {
+		return nil, err
+	}
+	return in.updateWrapper(bound[0], bound[1])
+}
+
+// builtinWraps returns a decorator that applies update_wrapper with wrapped.
+func (in *Interpreter) builtinWraps(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("wraps", args, kwargs, 1, "wrapped")
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := bound[0]
+	return &object.Builtin{Name: "wraps", Fn: builtin(func(in *Interpreter, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("wraps", args, kwargs, 1, "wrapper")
+		if err != nil {
+			return nil, err
+		}
+		return in.updateWrapper(bound[0], wrapped)
+	})}, nil
+}
+
+// updateWrapper makes wrapper look like wrapped: it copies the name and
+// docstring, merges the function attributes and sets __wrapped__.
+func (in *Interpreter) updateWrapper(wrapper, wrapped object.Object) (object.Object, error) {
+	for _, name := range wrapperAssignments {
+		val, err := in.getAttr(wrapped, name)
+		if exc, ok := err.(*object.Exception); ok && exc.Class.IsSubclass(object.AttributeError) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if err := in.setAttr(wrapper, name, val); err != nil {
+			return nil, err
+		}
+	}
+
+	if from, ok := wrapped.(*object.Function); ok {
+		if to, ok := wrapper.(*object.Function); ok {
+			maps.Copy(to.Attrs, from.Attrs)
+		}
+	}
+
+	if err := in.setAttr(wrapper, "__wrapped__", wrapped); err != nil {
+		return nil, err
+	}
+	return wrapper, nil
+}
@@ -0,0 +1,252 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"snek/lexer"
+	"snek/object"
+	"snek/parser"
+	"strings"
+)
+
+// Importer holds the state of the import system: where modules are found and
+// the ones imported so far.
+type Importer struct {
+	// Loaders are searched in order for modules that are not registered
+	// with RegisterModule.
+	Loaders []ModuleLoader
+
+	in *Interpreter
+
+	// modules caches every module imported so far by its full name. A module
+	// is cached before its code runs, so circular imports see it partially
+	// initialized.
+	modules map[string]*object.Module
+}
+
+// NewImporter returns the importer of a new interpreter that finds modules
+// with loaders. Modules it loads import through the same importer.
+func NewImporter(loaders ...ModuleLoader) *Importer {
+	return New(WithLoaders(loaders...)).imports
+}
+
+func (in *Interpreter) evalImport(node *ast.ImportNode, env *object.Environment) (object.Object, error) {
+	for _, alias := range node.Names {
+		mod, err := in.imports.Import(alias.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if alias.AsName != "" {
+			env.Set(alias.AsName, mod)
+			continue
+		}
+
+		// 'import a.b' binds the top-level package a.
+		top, _, _ := strings.Cut(alias.Name, ".")
+		env.Set(top, in.imports.modules[top])
+	}
+
+	return object.NONE, nil
+}
+
+func (in *Interpreter) evalImportFrom(node *ast.ImportFromNode, env *object.Environment) (object.Object, error) {
+	name, err := resolveName(node.Module, node.Level, env)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := in.imports.Import(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(node.Names) == 1 && node.Names[0].Name == "*" {
+		return object.NONE, in.imports.importAll(mod, env)
+	}
+
+	for _, alias := range node.Names {
+		val, err := in.imports.importName(mod, alias.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if alias.AsName != "" {
+			env.Set(alias.AsName, val)
+		} else {
+			env.Set(alias.Name, val)
+		}
+	}
+
+	return object.NONE, nil
+}
+
+// resolveName turns a relative module name into an absolute one, using the
+// __package__ of the importing module.
+func resolveName(module string, level int, env *object.Environment) (string, error) {
+	if level == 0 {
+		return module, nil
+	}
+
+	pkg := ""
+	if val, ok := env.Get("__package__"); ok {
+		if s, ok := val.(*object.String); ok {
+			pkg = s.Value
+		}
+	}
+	if pkg == "" {
+		return "", object.NewError(object.ImportError, "attempted relative import with no known parent package")
+	}
+
+	parts := strings.Split(pkg, ".")
+	if level > len(parts) {
+		return "", object.NewError(object.ImportError, "attempted relative import beyond top-level package")
+	}
+
+	name := strings.Join(parts[:len(parts)-level+1], ".")
+	if module != "" {
+		name += "." + module
+	}
+	return name, nil
+}
+
+// Import returns the module with the given dotted name, importing it and its
+// parent packages first if needed.
+func (im *Importer) Import(name string) (*object.Module, error) {
+	if mod, ok := im.modules[name]; ok {
+		return mod, nil
+	}
+
+	var parent *object.Module
+	i := strings.LastIndex(name, ".")
+	if i >= 0 {
+		var err error
+		parent, err = im.Import(name[:i])
+		if err != nil {
+			return nil, err
+		}
+		if !parent.IsPackage {
+			return nil, object.NewError(object.ModuleNotFoundError, "No module named '%s'; '%s' is not a package", name, parent.Name)
+		}
+	}
+
+	spec, err := im.findModule(name)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return nil, object.NewError(object.ModuleNotFoundError, "No module named '%s'", name)
+	}
+
+	mod, err := im.loadModule(name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		parent.Env.Set(name[i+1:], mod)
+	}
+	return mod, nil
+}
+
+func (im *Importer) loadModule(name string, spec *ModuleSpec) (*object.Module, error) {
+	mod := object.NewModule(name, spec.Path, spec.IsPackage)
+
+	if spec.Init != nil {
+		im.modules[name] = mod
+		if err := spec.Init(mod); err != nil {
+			delete(im.modules, name)
+			return nil, err
+		}
+		return mod, nil
+	}
+
+	prog, err := parseSource(spec.Source, spec.Path, im.in.lexerOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	im.modules[name] = mod
+	if _, err := im.in.Eval(prog, mod.Env); err != nil {
+		delete(im.modules, name)
+		return nil, err
+	}
+	return mod, nil
+}
+
+func parseSource(src, path string, opts ...lexer.Option) (ast.Node, error) {
+	l := lexer.New(src, opts...)
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		return nil, object.NewError(lexerErrorClass(errs[0]), "%s (%s)", errs[0], path)
+	}
+
+	prog, err := parser.New(tokens).ParseFile()
+	if err != nil {
+		return nil, object.NewError(object.SyntaxError, "%s (%s)", err, path)
+	}
+	return prog, nil
+}
+
+func lexerErrorClass(err *lexer.Error) *object.Class {
+	switch err.Kind {
+	case "IndentationError":
+		return object.IndentationError
+	case "TabError":
+		return object.TabError
+	}
+	return object.SyntaxError
+}
+
+// importName fetches name from a module for 'from module import name',
+// falling back to importing a submodule of a package.
+func (im *Importer) importName(mod *object.Module, name string) (object.Object, error) {
+	if val, ok := mod.Env.Get(name); ok {
+		return val, nil
+	}
+
+	if mod.IsPackage {
+		spec, err := im.findModule(mod.Name + "." + name)
+		if err != nil {
+			return nil, err
+		}
+		if spec != nil {
+			return im.Import(mod.Name + "." + name)
+		}
+	}
+
+	return nil, object.NewError(object.ImportError, "cannot import name '%s' from '%s' (%s)", name, mod.Name, mod.Path)
+}
+
+// importAll binds the names listed in the module's __all__, or else all of
+// its names that do not start with an underscore.
+func (im *Importer) importAll(mod *object.Module, env *object.Environment) error {
+	names := []string{}
+	if all, ok := mod.Env.Get("__all__"); ok {
+		items, err := im.in.collect(all)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			s, ok := item.(*object.String)
+			if !ok {
+				return object.NewError(object.TypeError, "Item in %s.__all__ must be str, not %s", mod.Name, item.Type())
+			}
+			names = append(names, s.Value)
+		}
+	} else {
+		for _, name := range mod.Env.Names() {
+			if !strings.HasPrefix(name, "_") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		val, err := im.importName(mod, name)
+		if err != nil {
+			return err
+		}
+		env.Set(name, val)
+	}
+	return nil
+}
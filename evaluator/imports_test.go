@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"snek/lexer"
+	"snek/object"
+	"testing"
+	"testing/fstest"
+)
+
+func TestImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.snek":                 {Data: []byte("import b\nx = 1\n")},
+		"b.snek":                 {Data: []byte("import a\ndef f():\n    return a.x\n")},
+		"c.snek":                 {Data: []byte("from d import y\nx = 1\n")},
+		"d.snek":                 {Data: []byte("from c import x\ny = 1\n")},
+		"pkg/__init__.snek":      {Data: []byte("from .sub import y\n")},
+		"pkg/sub.snek":           {Data: []byte("from . import z\ny = z.v + 1\n")},
+		"pkg/z.snek":             {Data: []byte("v = 1\n")},
+		"pkg/deep/__init__.snek": {Data: []byte("from ..z import v\nfrom ...z import v\n")},
+		"bad.snek":               {Data: []byte("print('running')\nraise ValueError('boom')\n")},
+		"once.snek":              {Data: []byte("print('running')\n")},
+		"syntax.snek":            {Data: []byte("x = \n")},
+	}
+	natives := NativeLoader{
+		"greet": func(mod *object.Module) error {
+			mod.Env.Set("hello", &object.String{Value: "hi"})
+			return nil
+		},
+		"native":     func(mod *object.Module) error { return nil },
+		"native.sub": func(mod *object.Module) error { return object.NewError(object.ValueError, "no sub") },
+	}
+
+	tests := []outputTest{
+		{"import a, b\nprint(b.f())\n", "1\n"},
+		{"try:\n    import c\nexcept ImportError as e:\n    print(e)\n", "cannot import name 'x' from 'c' (c.snek)\n"},
+		{"import pkg\nprint(pkg.y, pkg.z.v)\n", "2 1\n"},
+		{"from pkg.sub import y\nprint(y)\n", "2\n"},
+		{"try:\n    import pkg.deep\nexcept ImportError as e:\n    print(e)\n", "attempted relative import beyond top-level package\n"},
+		{"try:\n    from . import a\nexcept ImportError as e:\n    print(e)\n", "attempted relative import with no known parent package\n"},
+		{"for i in range(2):\n    try:\n        import bad\n    except ValueError:\n        print('failed')\n", "running\nfailed\nrunning\nfailed\n"},
+		{"try:\n    import bad\nexcept ValueError:\n    pass\ntry:\n    bad\nexcept NameError:\n    print('unbound')\n", "running\nunbound\n"},
+		{"try:\n    import syntax\nexcept SyntaxError:\n    print('syntax')\n", "syntax\n"},
+		{"import once\nimport once\nfrom once import __name__ as name\nprint(name)\n", "running\nonce\n"},
+		{"import greet\nfrom greet import hello\nprint(greet.hello, hello)\n", "hi hi\n"},
+		{"for i in range(2):\n    try:\n        import native.sub\n    except ValueError as e:\n        print(e)\nimport native\ntry:\n    native.sub\nexcept AttributeError:\n    print('no attribute')\n", "no sub\nno sub\nno attribute\n"},
+		{"try:\n    import missing\nexcept ModuleNotFoundError as e:\n    print(e)\n", "No module named 'missing'\n"},
+		{"try:\n    import once.sub\nexcept ModuleNotFoundError as e:\n    print(e)\n", "running\nNo module named 'once.sub'; 'once' is not a package\n"},
+	}
+
+	testOutput(t, tests, WithLoaders(natives, NewFSLoader(fsys, "")))
+}
+
+func TestImporter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.snek": {Data: []byte("import b\nx = b.y + 1\n")},
+		"b.snek": {Data: []byte("y = 1\n")},
+	}
+	a, err := NewImporter(NewFSLoader(fsys, "")).Import("a")
+	if err != nil {
+		t.Fatalf("importing a failed: %v", err)
+	}
+	if x, ok := a.Env.Get("x"); !ok || x.Inspect() != "2" {
+		t.Errorf("a.x = %v, want 2", x)
+	}
+
+	other := fstest.MapFS{"b.snek": {Data: []byte("y = 2\n")}}
+	b, err := NewImporter(NewFSLoader(other, "")).Import("b")
+	if err != nil {
+		t.Fatalf("importing b failed: %v", err)
+	}
+	if y, ok := b.Env.Get("y"); !ok || y.Inspect() != "2" {
+		t.Errorf("b.y = %v, want 2", y)
+	}
+}
+
+func TestImportLexerOptions(t *testing.T) {
+	fsys := fstest.MapFS{"tabs.snek": {Data: []byte("if True:\n\tx = 1\n    y = 2\n")}}
+	src := "try:\n    import tabs\n    print(tabs.x, tabs.y)\nexcept IndentationError as e:\n    print(type(e).__name__)\n"
+	testOutput(t, []outputTest{{src, "IndentationError\n"}}, WithLoaders(NewFSLoader(fsys, "")))
+	testOutput(t, []outputTest{{src, "1 2\n"}}, WithLoaders(NewFSLoader(fsys, "")),
+		WithLexerOptions(lexer.WithTabWidth(4), lexer.WithStrictTabs(false)))
+}
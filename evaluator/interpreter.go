@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+	"snek/ast"
+	"snek/lexer"
+	"snek/object"
+)
+
+// RECURSION_LIMIT is how deep calls to Python functions may nest before
+// RecursionError is raised, unless set with WithRecursionLimit.
+const RECURSION_LIMIT = 1000
+
+// Interpreter holds the state of running code: imported modules, where print
+// writes and how deep calls are nested. Separate interpreters may be used from
+// separate goroutines, but each runs one thing at a time.
+type Interpreter struct {
+	imports        *Importer
+	stdout         io.Writer
+	optimize       bool
+	recursionLimit int
+	lexerOpts      []lexer.Option
+
+	// handling is the exception whose except clause is running, or nil. A
+	// bare raise re-raises it, even from a function called by the clause.
+	handling *object.Exception
+
+	// depth counts the function bodies being evaluated. A suspended
+	// generator does not count until it is resumed.
+	depth int
+
+	// reprActive holds the containers whose repr is being built, so that one
+	// that contains itself is shown as [...] or {...} instead of recursing.
+	reprActive map[object.Object]bool
+
+	// locals caches the names local to each function definition or lambda.
+	locals map[ast.Node]map[string]bool
+}
+
+type Option func(*Interpreter)
+
+// WithLoaders makes imports search loaders, in order, for modules not
+// registered with RegisterModule. The default is the current directory.
+func WithLoaders(loaders ...ModuleLoader) Option {
+	return func(in *Interpreter) { in.imports.Loaders = loaders }
+}
+
+// WithStdout sends the output of print to w instead of os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(in *Interpreter) { in.stdout = w }
+}
+
+// WithOptimize skips assert statements, like running python with -O.
+func WithOptimize() Option {
+	return func(in *Interpreter) { in.optimize = true }
+}
+
+func WithRecursionLimit(limit int) Option {
+	return func(in *Interpreter) { in.recursionLimit = limit }
+}
+
+// WithLexerOptions sets the options imported modules are tokenized with.
+func WithLexerOptions(opts ...lexer.Option) Option {
+	return func(in *Interpreter) { in.lexerOpts = opts }
+}
+
+func New(opts ...Option) *Interpreter {
+	in := &Interpreter{
+		stdout:         os.Stdout,
+		recursionLimit: RECURSION_LIMIT,
+		reprActive:     map[object.Object]bool{},
+		locals:         map[ast.Node]map[string]bool{},
+	}
+	in.imports = &Importer{
+		Loaders: []ModuleLoader{NewDirLoader(".")},
+		in:      in,
+		modules: map[string]*object.Module{},
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Importer returns the importer used by import statements run by in.
+func (in *Interpreter) Importer() *Importer {
+	return in.imports
+}
+
+// LexerOptions returns the options source run by in is tokenized with.
+func (in *Interpreter) LexerOptions() []lexer.Option {
+	return in.lexerOpts
+}
+
+// Call calls fn with the given arguments, as a call in Python code would.
+func (in *Interpreter) Call(fn object.Object, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	return in.callObject(fn, args, kwargs)
+}
+
+// builtin adapts a builtin implemented against the interpreter, which
+// object.BuiltinFunction receives as its caller.
+func builtin(fn func(*Interpreter, []object.Object, []object.Keyword) (object.Object, error)) object.BuiltinFunction {
+	return func(c object.Caller, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return fn(c.(*Interpreter), args, kwargs)
+	}
+}
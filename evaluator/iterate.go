@@ -0,0 +1,376 @@
+package evaluator
+
+import (
+	"snek/object"
+	"strconv"
+	"strings"
+)
+
+// lookupIter returns an iterator over obj, or nil if obj is not iterable.
+func (in *Interpreter) lookupIter(obj object.Object) (object.Iterator, error) {
+	switch obj := obj.(type) {
+	case object.Iterator:
+		return obj, nil
+	case *object.List:
+		return &object.ListIterator{List: obj}, nil
+	case *object.Tuple:
+		return &object.TupleIterator{Tuple: obj}, nil
+	case *object.String:
+		return object.NewStringIterator(obj), nil
+	case *object.Dict:
+		return object.NewDictIterator(&object.DictView{Dict: obj, Kind: object.DICT_KEYS}), nil
+	case *object.DictView:
+		return object.NewDictIterator(obj), nil
+	case *object.Range:
+		return &object.RangeIterator{Range: obj}, nil
+	case *object.Set:
+		return object.NewSetIterator(obj), nil
+	case *object.Instance:
+		return in.instanceIter(obj)
+	}
+	return nil, nil
+}
+
+func (in *Interpreter) iterate(obj object.Object) (object.Iterator, error) {
+	it, err := in.lookupIter(obj)
+	if err == nil && it == nil {
+		return nil, object.NewError(object.TypeError, "'%s' object is not iterable", obj.Type())
+	}
+	return it, err
+}
+
+// collect drains an iterable into a new slice.
+func (in *Interpreter) collect(obj object.Object) ([]object.Object, error) {
+	it, err := in.iterate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []object.Object{}
+	for {
+		item, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return items, nil
+		}
+		items = append(items, item)
+	}
+}
+
+func (in *Interpreter) contains(container, item object.Object) (bool, error) {
+	if res, ok, err := in.callSpecial(container, "__contains__", item); ok {
+		if err != nil {
+			return false, err
+		}
+		return in.isTruthy(res)
+	}
+
+	switch c := container.(type) {
+	case *object.String:
+		s, ok := item.(*object.String)
+		if !ok {
+			return false, object.NewError(object.TypeError, "'in <string>' requires string as left operand, not %s", item.Type())
+		}
+		return strings.Contains(c.Value, s.Value), nil
+	case *object.Dict:
+		return in.dictContains(c, item)
+	case *object.Set:
+		key, err := in.hashKey(c, item)
+		if err != nil {
+			return false, err
+		}
+		return c.Contains(key), nil
+	case *object.DictView:
+		switch c.Kind {
+		case object.DICT_KEYS:
+			return in.dictContains(c.Dict, item)
+		case object.DICT_ITEMS:
+			pair, ok := item.(*object.Tuple)
+			if !ok || len(pair.Elements) != 2 {
+				return false, nil
+			}
+			key, err := in.hashKey(c.Dict, pair.Elements[0])
+			if err != nil {
+				return false, err
+			}
+			val, ok := c.Dict.Get(key)
+			if !ok {
+				return false, nil
+			}
+			return in.equals(val, pair.Elements[1])
+		}
+	case *object.Range:
+		if n, ok := toNumber(item).(*object.Integer); ok {
+			return c.Contains(n.Value), nil
+		}
+	}
+
+	it, err := in.lookupIter(container)
+	if err != nil {
+		return false, err
+	}
+	if it == nil {
+		return false, object.NewError(object.TypeError, "argument of type '%s' is not iterable", container.Type())
+	}
+
+	for {
+		elem, err := it.Next()
+		if err != nil || elem == nil {
+			return false, err
+		}
+
+		eq, err := in.equals(elem, item)
+		if err != nil || eq {
+			return eq, err
+		}
+	}
+}
+
+func (in *Interpreter) dictContains(d *object.Dict, item object.Object) (bool, error) {
+	key, err := in.hashKey(d, item)
+	if err != nil {
+		return false, err
+	}
+	_, ok := d.Get(key)
+	return ok, nil
+}
+
+type enumerateIterator struct {
+	it    object.Iterator
+	count int64
+}
+
+func (in *Interpreter) newEnumerate(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("enumerate", args, kwargs, 1, "iterable", "start")
+	if err != nil {
+		return nil, err
+	}
+
+	e := &enumerateIterator{}
+	if bound[1] != nil {
+		if e.count, err = toInt(bound[1]); err != nil {
+			return nil, err
+		}
+	}
+	e.it, err = in.iterate(bound[0])
+	return e, err
+}
+
+func (e *enumerateIterator) Type() object.ObjectType { return "enumerate" }
+func (e *enumerateIterator) Inspect() string         { return "<enumerate object>" }
+
+func (e *enumerateIterator) Next() (object.Object, error) {
+	item, err := e.it.Next()
+	if err != nil || item == nil {
+		return nil, err
+	}
+	e.count++
+	return &object.Tuple{Elements: []object.Object{&object.Integer{Value: e.count - 1}, item}}, nil
+}
+
+type zipIterator struct {
+	its    []object.Iterator
+	strict bool
+}
+
+func (in *Interpreter) newZip(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	z := &zipIterator{}
+	for _, kw := range kwargs {
+		if kw.Name != "strict" {
+			return nil, object.NewError(object.TypeError, "zip() got an unexpected keyword argument '%s'", kw.Name)
+		}
+		strict, err := in.isTruthy(kw.Value)
+		if err != nil {
+			return nil, err
+		}
+		z.strict = strict
+	}
+
+	for _, arg := range args {
+		it, err := in.iterate(arg)
+		if err != nil {
+			return nil, err
+		}
+		z.its = append(z.its, it)
+	}
+	return z, nil
+}
+
+func (z *zipIterator) Type() object.ObjectType { return "zip" }
+func (z *zipIterator) Inspect() string         { return "<zip object>" }
+
+func (z *zipIterator) Next() (object.Object, error) {
+	if len(z.its) == 0 {
+		return nil, nil
+	}
+
+	items := make([]object.Object, len(z.its))
+	for i, it := range z.its {
+		item, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, z.checkExhausted(i)
+		}
+		items[i] = item
+	}
+	return &object.Tuple{Elements: items}, nil
+}
+
+// checkExhausted enforces strict=True once the i-th iterator runs out.
+func (z *zipIterator) checkExhausted(i int) error {
+	its := z.its
+	z.its = nil
+	if !z.strict {
+		return nil
+	}
+
+	if i > 0 {
+		return object.NewError(object.ValueError, "zip() argument %d is shorter than argument%s", i+1, shorterThan(i))
+	}
+	for j, it := range its[1:] {
+		item, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			return object.NewError(object.ValueError, "zip() argument %d is longer than argument%s", j+2, shorterThan(j+1))
+		}
+	}
+	return nil
+}
+
+func shorterThan(n int) string {
+	if n == 1 {
+		return " 1"
+	}
+	return "s 1-" + strconv.Itoa(n)
+}
+
+type mapIterator struct {
+	in  *Interpreter
+	fn  object.Object
+	its []object.Iterator
+}
+
+func (in *Interpreter) newMap(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	if len(kwargs) > 0 {
+		return nil, object.NewError(object.TypeError, "map() takes no keyword arguments")
+	}
+	if len(args) < 2 {
+		return nil, object.NewError(object.TypeError, "map() must have at least two arguments.")
+	}
+
+	m := &mapIterator{in: in, fn: args[0]}
+	for _, arg := range args[1:] {
+		it, err := in.iterate(arg)
+		if err != nil {
+			return nil, err
+		}
+		m.its = append(m.its, it)
+	}
+	return m, nil
+}
+
+func (m *mapIterator) Type() object.ObjectType { return "map" }
+func (m *mapIterator) Inspect() string         { return "<map object>" }
+
+func (m *mapIterator) Next() (object.Object, error) {
+	args := make([]object.Object, len(m.its))
+	for i, it := range m.its {
+		item, err := it.Next()
+		if err != nil || item == nil {
+			return nil, err
+		}
+		args[i] = item
+	}
+	return m.in.callObject(m.fn, args, nil)
+}
+
+type filterIterator struct {
+	in *Interpreter
+	fn object.Object
+	it object.Iterator
+}
+
+func (in *Interpreter) newFilter(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("filter", args, kwargs, 2, "function", "iterable")
+	if err != nil {
+		return nil, err
+	}
+	it, err := in.iterate(bound[1])
+	return &filterIterator{in: in, fn: bound[0], it: it}, err
+}
+
+func (f *filterIterator) Type() object.ObjectType { return "filter" }
+func (f *filterIterator) Inspect() string         { return "<filter object>" }
+
+func (f *filterIterator) Next() (object.Object, error) {
+	for {
+		item, err := f.it.Next()
+		if err != nil || item == nil {
+			return nil, err
+		}
+
+		test := item
+		if f.fn != object.NONE {
+			if test, err = f.in.callObject(f.fn, []object.Object{item}, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		truthy, err := f.in.isTruthy(test)
+		if err != nil {
+			return nil, err
+		}
+		if truthy {
+			return item, nil
+		}
+	}
+}
+
+// reversedIterator walks a sequence backwards by index.
+type reversedIterator struct {
+	in    *Interpreter
+	seq   object.Object
+	index int
+}
+
+func (in *Interpreter) newReversed(args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("reversed", args, kwargs, 1, "sequence")
+	if err != nil {
+		return nil, err
+	}
+
+	switch seq := bound[0].(type) {
+	case *object.Range:
+		n := seq.Len()
+		reversed := &object.Range{Start: seq.Item(n - 1), Stop: seq.Start - seq.Step, Step: -seq.Step}
+		if n == 0 {
+			reversed = &object.Range{Step: 1}
+		}
+		return &object.RangeIterator{Range: reversed}, nil
+	case *object.List, *object.Tuple, *object.String:
+		n, err := in.lengthOf(seq)
+		return &reversedIterator{in: in, seq: seq, index: n}, err
+	}
+
+	return nil, object.NewError(object.TypeError, "'%s' object is not reversible", bound[0].Type())
+}
+
+func (r *reversedIterator) Type() object.ObjectType { return "reversed" }
+func (r *reversedIterator) Inspect() string         { return "<reversed object>" }
+
+func (r *reversedIterator) Next() (object.Object, error) {
+	if n, _ := r.in.lengthOf(r.seq); r.index > n {
+		r.index = 0
+	}
+	if r.index <= 0 {
+		return nil, nil
+	}
+	r.index--
+	return r.in.getItem(r.seq, &object.Integer{Value: int64(r.index)})
+}
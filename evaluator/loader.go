@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"snek/object"
+	"strings"
+)
+
+// ModuleLoader finds modules for the import system.
+type ModuleLoader interface {
+	// Load returns the module with the given dotted name, or nil if this
+	// loader does not provide it.
+	Load(name string) (*ModuleSpec, error)
+}
+
+// ModuleSpec describes a module found by a loader. Source modules run Source
+// in the new module; native modules are populated by Init instead.
+type ModuleSpec struct {
+	Path      string
+	IsPackage bool
+	Source    string
+	Init      func(*object.Module) error
+}
+
+// FSLoader loads .snek files and packages from a file system, such as an
+// embed.FS, a zip archive or a directory on disk.
+type FSLoader struct {
+	FS   fs.FS
+	Root string // Prefixed to file paths in module reprs and __file__
+}
+
+func NewFSLoader(fsys fs.FS, root string) *FSLoader {
+	return &FSLoader{FS: fsys, Root: root}
+}
+
+func NewDirLoader(dir string) *FSLoader {
+	return &FSLoader{FS: os.DirFS(dir), Root: dir}
+}
+
+func (l *FSLoader) Load(name string) (*ModuleSpec, error) {
+	base := strings.ReplaceAll(name, ".", "/")
+
+	for _, candidate := range []struct {
+		file      string
+		isPackage bool
+	}{
+		{path.Join(base, "__init__.snek"), true},
+		{base + ".snek", false},
+	} {
+		src, err := fs.ReadFile(l.FS, candidate.file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, object.NewError(object.ImportError, "%s", err)
+		}
+
+		return &ModuleSpec{
+			Path:      filepath.Join(l.Root, filepath.FromSlash(candidate.file)),
+			IsPackage: candidate.isPackage,
+			Source:    string(src),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// NativeLoader serves modules implemented in Go, keyed by import name.
+type NativeLoader map[string]func(*object.Module) error
+
+func (l NativeLoader) Load(name string) (*ModuleSpec, error) {
+	init, ok := l[name]
+	if !ok {
+		return nil, nil
+	}
+
+	spec := &ModuleSpec{Init: init}
+	for other := range l {
+		if strings.HasPrefix(other, name+".") {
+			spec.IsPackage = true
+		}
+	}
+	return spec, nil
+}
+
+// natives holds the modules registered with RegisterModule. Every importer
+// finds them before anything on its Loaders.
+var natives = NativeLoader{}
+
+// RegisterModule makes a Go module importable under name. init fills in the
+// module's attributes when it is first imported. It is meant to be called
+// from init functions; modules for a single importer go on its Loaders as a
+// NativeLoader instead.
+func RegisterModule(name string, init func(*object.Module) error) {
+	natives[name] = init
+}
+
+func (im *Importer) findModule(name string) (*ModuleSpec, error) {
+	for _, loader := range append([]ModuleLoader{natives}, im.Loaders...) {
+		spec, err := loader.Load(name)
+		if err != nil || spec != nil {
+			return spec, err
+		}
+	}
+	return nil, nil
+}
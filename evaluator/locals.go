@@ -0,0 +1,205 @@
+package evaluator
+
+import (
+	"snek/ast"
+	"strings"
+)
+
+// localNames returns the names bound by a function's parameters and body,
+// which are local to each call. The result is cached for def, the function
+// definition or lambda node.
+func (in *Interpreter) localNames(def ast.Node, params []ast.Node, body ast.Node) map[string]bool {
+	if names, ok := in.locals[def]; ok {
+		return names
+	}
+
+	names := map[string]bool{}
+	for _, p := range params {
+		if param := p.(*ast.ParamNode); param.Name != nil {
+			names[param.Name.String()] = true
+		}
+	}
+	collectLocals(body, names)
+	in.locals[def] = names
+	return names
+}
+
+// collectLocals adds the names node binds to names. Nested functions and
+// classes bind only their own name; their bodies have scopes of their own.
+func collectLocals(node ast.Node, names map[string]bool) {
+	switch n := node.(type) {
+	case *ast.BlockNode:
+		collectAll(n.Statements, names)
+	case *ast.IdentifierNode:
+		// A name being read binds nothing.
+	case *ast.AssignmentNode:
+		for _, target := range n.Targets {
+			collectTargets(target, names)
+		}
+		collectLocals(n.Value, names)
+	case *ast.NamedExprNode:
+		collectTargets(n.Target, names)
+		collectLocals(n.Value, names)
+	case *ast.DelNode:
+		for _, target := range n.Targets {
+			collectTargets(target, names)
+		}
+	case *ast.ForNode:
+		collectTargets(n.Targets, names)
+		collectAll([]ast.Node{n.Values, n.Body, n.Else}, names)
+	case *ast.WithNode:
+		for _, item := range n.Items {
+			collectLocals(item.Context, names)
+			collectTargets(item.Target, names)
+		}
+		collectLocals(n.Body, names)
+	case *ast.TryNode:
+		collectAll([]ast.Node{n.Body, n.Else, n.Finally}, names)
+		for _, h := range n.Handlers {
+			collectTargets(h.Name, names)
+			collectAll([]ast.Node{h.Type, h.Body}, names)
+		}
+	case *ast.ImportNode:
+		for _, alias := range n.Names {
+			if alias.AsName != "" {
+				names[alias.AsName] = true
+			} else {
+				names[strings.Split(alias.Name, ".")[0]] = true
+			}
+		}
+	case *ast.ImportFromNode:
+		for _, alias := range n.Names {
+			if alias.AsName != "" {
+				names[alias.AsName] = true
+			} else if alias.Name != "*" {
+				names[alias.Name] = true
+			}
+		}
+	case *ast.FunctionDefNode:
+		collectTargets(n.Name, names)
+		collectAll(n.Decorators, names)
+		for _, p := range n.Params {
+			collectLocals(p.(*ast.ParamNode).DefaultValue, names)
+		}
+	case *ast.ClassDefNode:
+		collectTargets(n.Name, names)
+		collectAll(n.Decorators, names)
+		collectAll(n.Bases, names)
+	case *ast.LambdaNode:
+		for _, p := range n.Params {
+			collectLocals(p.(*ast.ParamNode).DefaultValue, names)
+		}
+	case *ast.IfNode:
+		collectAll([]ast.Node{n.Condition, n.Body, n.Else}, names)
+	case *ast.WhileNode:
+		collectAll([]ast.Node{n.Condition, n.Body, n.Else}, names)
+	case *ast.ConditionalNode:
+		collectAll([]ast.Node{n.Condition, n.Body, n.Else}, names)
+	case *ast.MatchNode:
+		collectLocals(n.Subject, names)
+		for _, c := range n.Cases {
+			collectAll([]ast.Node{c.Pattern, c.Guard, c.Body}, names)
+		}
+	case *ast.MatchAsNode:
+		collectTargets(n.Name, names)
+		collectLocals(n.Pattern, names)
+	case *ast.MatchStarNode:
+		collectTargets(n.Name, names)
+	case *ast.MatchOrNode:
+		collectAll(n.Patterns, names)
+	case *ast.MatchSequenceNode:
+		collectAll(n.Patterns, names)
+	case *ast.MatchMappingNode:
+		collectAll(n.Patterns, names)
+		collectTargets(n.Rest, names)
+	case *ast.MatchClassNode:
+		collectAll(n.Patterns, names)
+		collectAll(n.KwdPatterns, names)
+	case *ast.ReturnNode:
+		collectLocals(n.Value, names)
+	case *ast.YieldNode:
+		collectLocals(n.Value, names)
+	case *ast.AssertNode:
+		collectAll([]ast.Node{n.Test, n.Message}, names)
+	case *ast.RaiseNode:
+		collectAll([]ast.Node{n.Exception, n.Cause}, names)
+	case *ast.PrefixNode:
+		collectLocals(n.Right, names)
+	case *ast.InfixNode:
+		collectAll([]ast.Node{n.Left, n.Right}, names)
+	case *ast.CallNode:
+		collectLocals(n.Function, names)
+		collectAll(n.Args, names)
+	case *ast.KeywordNode:
+		collectLocals(n.Value, names)
+	case *ast.SliceNode:
+		collectAll([]ast.Node{n.Left, n.Index}, names)
+	case *ast.SliceExprNode:
+		collectAll([]ast.Node{n.Lower, n.Upper, n.Step}, names)
+	case *ast.ExpressionsNode:
+		collectAll(n.Expressions, names)
+	case *ast.TupleNode:
+		collectAll(n.Elements, names)
+	case *ast.ListNode:
+		collectAll(n.Elements, names)
+	case *ast.SetNode:
+		collectAll(n.Elements, names)
+	case *ast.DictNode:
+		collectAll(n.Keys, names)
+		collectAll(n.Values, names)
+	case *ast.StarredNode:
+		collectLocals(n.Value, names)
+	case *ast.DoubleStarredNode:
+		collectLocals(n.Value, names)
+	case *ast.ListCompNode:
+		collectComprehension([]ast.Node{n.Element}, n.Clauses, names)
+	case *ast.SetCompNode:
+		collectComprehension([]ast.Node{n.Element}, n.Clauses, names)
+	case *ast.DictCompNode:
+		collectComprehension([]ast.Node{n.Key, n.Value}, n.Clauses, names)
+	case *ast.GeneratorExpNode:
+		collectComprehension([]ast.Node{n.Element}, n.Clauses, names)
+	}
+}
+
+func collectAll(nodes []ast.Node, names map[string]bool) {
+	for _, node := range nodes {
+		collectLocals(node, names)
+	}
+}
+
+// collectComprehension adds the assignment expression targets of a
+// comprehension, which bind in the enclosing function. Its loop variables
+// belong to the comprehension.
+func collectComprehension(elements []ast.Node, clauses []*ast.ComprehensionNode, names map[string]bool) {
+	collectAll(elements, names)
+	for _, clause := range clauses {
+		collectLocals(clause.Values, names)
+		collectAll(clause.Ifs, names)
+	}
+}
+
+// collectTargets adds the names an assignment or del target binds. Items and
+// attributes bind nothing, though their expressions may.
+func collectTargets(target ast.Node, names map[string]bool) {
+	switch t := target.(type) {
+	case *ast.IdentifierNode:
+		names[t.Name] = true
+	case *ast.TupleNode:
+		for _, elem := range t.Elements {
+			collectTargets(elem, names)
+		}
+	case *ast.ListNode:
+		for _, elem := range t.Elements {
+			collectTargets(elem, names)
+		}
+	case *ast.ExpressionsNode:
+		for _, elem := range t.Expressions {
+			collectTargets(elem, names)
+		}
+	case *ast.StarredNode:
+		collectTargets(t.Value, names)
+	default:
+		collectLocals(target, names)
+	}
+}
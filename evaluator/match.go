@@ -0,0 +1,291 @@
+package evaluator
+
+import (
+	"maps"
+	"slices"
+	"snek/ast"
+	"snek/object"
+)
+
+// selfMatchingClasses match the whole subject against a single positional
+// sub-pattern, as in case int(n).
+var selfMatchingClasses = map[*object.Class]bool{
+	boolClass:  true,
+	dictClass:  true,
+	floatClass: true,
+	intClass:   true,
+	listClass:  true,
+	setClass:   true,
+	strClass:   true,
+	tupleClass: true,
+}
+
+func (in *Interpreter) evalMatch(node *ast.MatchNode, env *object.Environment) (object.Object, error) {
+	subject, err := in.Eval(node.Subject, env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range node.Cases {
+		bindings := map[string]object.Object{}
+		ok, err := in.matchPattern(c.Pattern, subject, env, bindings)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		// Names are bound before the guard runs, and stay bound if it fails.
+		for name, val := range bindings {
+			env.Set(name, val)
+		}
+
+		if c.Guard != nil {
+			ok, err := in.allTrue([]ast.Node{c.Guard}, env)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		return in.Eval(c.Body, env)
+	}
+
+	return object.NONE, nil
+}
+
+// matchPattern reports whether subject matches pattern, recording the names
+// the pattern captures in bindings.
+func (in *Interpreter) matchPattern(pattern ast.Node, subject object.Object, env *object.Environment, bindings map[string]object.Object) (bool, error) {
+	switch p := pattern.(type) {
+	case *ast.MatchValueNode:
+		val, err := in.Eval(p.Value, env)
+		if err != nil {
+			return false, err
+		}
+		return in.equals(subject, val)
+	case *ast.MatchSingletonNode:
+		val, err := in.Eval(p.Value, env)
+		if err != nil {
+			return false, err
+		}
+		return subject == val, nil
+	case *ast.MatchAsNode:
+		if p.Pattern != nil {
+			ok, err := in.matchPattern(p.Pattern, subject, env, bindings)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		if p.Name != nil {
+			bindings[p.Name.String()] = subject
+		}
+		return true, nil
+	case *ast.MatchOrNode:
+		for _, alt := range p.Patterns {
+			try := maps.Clone(bindings)
+			ok, err := in.matchPattern(alt, subject, env, try)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				maps.Copy(bindings, try)
+				return true, nil
+			}
+		}
+		return false, nil
+	case *ast.MatchSequenceNode:
+		return in.matchSequence(p, subject, env, bindings)
+	case *ast.MatchMappingNode:
+		return in.matchMapping(p, subject, env, bindings)
+	case *ast.MatchClassNode:
+		return in.matchClass(p, subject, env, bindings)
+	}
+
+	return false, object.NewError(object.NotImplementedError, "matching %T is not supported", pattern)
+}
+
+func (in *Interpreter) matchSequence(p *ast.MatchSequenceNode, subject object.Object, env *object.Environment, bindings map[string]object.Object) (bool, error) {
+	var items []object.Object
+	switch s := subject.(type) {
+	case *object.List:
+		items = slices.Clone(s.Elements)
+	case *object.Tuple:
+		items = s.Elements
+	case *object.Range:
+		var err error
+		if items, err = in.collect(s); err != nil {
+			return false, err
+		}
+	default:
+		return false, nil
+	}
+
+	star := slices.IndexFunc(p.Patterns, func(n ast.Node) bool {
+		_, ok := n.(*ast.MatchStarNode)
+		return ok
+	})
+	if star < 0 {
+		if len(items) != len(p.Patterns) {
+			return false, nil
+		}
+		return in.matchEach(p.Patterns, items, env, bindings)
+	}
+
+	after := len(p.Patterns) - star - 1
+	if len(items) < len(p.Patterns)-1 {
+		return false, nil
+	}
+
+	ok, err := in.matchEach(p.Patterns[:star], items[:star], env, bindings)
+	if err != nil || !ok {
+		return false, err
+	}
+	if name := p.Patterns[star].(*ast.MatchStarNode).Name; name != nil {
+		rest := slices.Clone(items[star : len(items)-after])
+		bindings[name.String()] = &object.List{Elements: rest}
+	}
+	return in.matchEach(p.Patterns[star+1:], items[len(items)-after:], env, bindings)
+}
+
+func (in *Interpreter) matchEach(patterns []ast.Node, items []object.Object, env *object.Environment, bindings map[string]object.Object) (bool, error) {
+	for i, pattern := range patterns {
+		ok, err := in.matchPattern(pattern, items[i], env, bindings)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (in *Interpreter) matchMapping(p *ast.MatchMappingNode, subject object.Object, env *object.Environment, bindings map[string]object.Object) (bool, error) {
+	dict, ok := subject.(*object.Dict)
+	if !ok {
+		return false, nil
+	}
+
+	seen := map[object.HashKey]bool{}
+	for i, keyNode := range p.Keys {
+		key, err := in.Eval(keyNode, env)
+		if err != nil {
+			return false, err
+		}
+		hash, err := in.hashKey(dict, key)
+		if err != nil {
+			return false, err
+		}
+		if seen[hash] {
+			return false, object.NewError(object.ValueError, "mapping pattern checks duplicate key (%s)", key.Inspect())
+		}
+		seen[hash] = true
+
+		val, ok := dict.Get(hash)
+		if !ok {
+			return false, nil
+		}
+		ok, err = in.matchPattern(p.Patterns[i], val, env, bindings)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if p.Rest != nil {
+		rest := object.NewDict()
+		for i := range dict.Len() {
+			pair := dict.PairAt(i)
+			hash, _ := object.Hash(pair.Key)
+			if !seen[hash] {
+				if err := in.dictSet(rest, pair.Key, pair.Value); err != nil {
+					return false, err
+				}
+			}
+		}
+		bindings[p.Rest.String()] = rest
+	}
+	return true, nil
+}
+
+func (in *Interpreter) matchClass(p *ast.MatchClassNode, subject object.Object, env *object.Environment, bindings map[string]object.Object) (bool, error) {
+	val, err := in.Eval(p.Class, env)
+	if err != nil {
+		return false, err
+	}
+	cls, ok := val.(*object.Class)
+	if !ok {
+		return false, object.NewError(object.TypeError, "called match pattern must be a class")
+	}
+	if !isInstance(subject, cls) {
+		return false, nil
+	}
+
+	attrs, patterns := []string{}, []ast.Node{}
+	if len(p.Patterns) > 0 && selfMatchingClasses[cls] {
+		if len(p.Patterns) > 1 {
+			return false, object.NewError(object.TypeError, "%s() accepts 1 positional sub-pattern (%d given)", cls.Name, len(p.Patterns))
+		}
+		ok, err := in.matchPattern(p.Patterns[0], subject, env, bindings)
+		if err != nil || !ok {
+			return false, err
+		}
+	} else if len(p.Patterns) > 0 {
+		names, err := matchArgs(cls)
+		if err != nil {
+			return false, err
+		}
+		if len(p.Patterns) > len(names) {
+			return false, object.NewError(object.TypeError, "%s() accepts %d positional %s (%d given)",
+				cls.Name, len(names), plural(len(names), "sub-pattern", "sub-patterns"), len(p.Patterns))
+		}
+		attrs = append(attrs, names[:len(p.Patterns)]...)
+		patterns = append(patterns, p.Patterns...)
+	}
+
+	for i, attr := range p.KwdAttrs {
+		if slices.Contains(attrs, attr.String()) {
+			return false, object.NewError(object.TypeError, "%s() got multiple sub-patterns for attribute '%s'", cls.Name, attr)
+		}
+		attrs = append(attrs, attr.String())
+		patterns = append(patterns, p.KwdPatterns[i])
+	}
+
+	for i, name := range attrs {
+		val, err := in.getAttr(subject, name)
+		if exc, ok := err.(*object.Exception); ok && exc.Class.IsSubclass(object.AttributeError) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		ok, err := in.matchPattern(patterns[i], val, env, bindings)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchArgs returns the attribute names in a class's __match_args__.
+func matchArgs(cls *object.Class) ([]string, error) {
+	val, ok := cls.LookupAttr("__match_args__")
+	if !ok {
+		return nil, nil
+	}
+
+	tuple, ok := val.(*object.Tuple)
+	if !ok {
+		return nil, object.NewError(object.TypeError, "%s.__match_args__ must be a tuple (got %s)", cls.Name, val.Type())
+	}
+
+	names := make([]string, len(tuple.Elements))
+	for i, elem := range tuple.Elements {
+		s, ok := elem.(*object.String)
+		if !ok {
+			return nil, object.NewError(object.TypeError, "__match_args__ elements must be strings (got %s)", elem.Type())
+		}
+		names[i] = s.Value
+	}
+	return names, nil
+}
@@ -0,0 +1,1088 @@
+package evaluator
+
+import (
+	"cmp"
+	"hash/fnv"
+	"math"
+	"slices"
+	"snek/ast"
+	"snek/object"
+	"snek/token"
+	"strings"
+)
+
+func (in *Interpreter) evalPrefix(node *ast.PrefixNode, env *object.Environment) (object.Object, error) {
+	right, err := in.Eval(node.Right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Operator == token.NOT {
+		truthy, err := in.isTruthy(right)
+		if err != nil {
+			return nil, err
+		}
+		return object.NativeBool(!truthy), nil
+	}
+
+	switch right := toNumber(right).(type) {
+	case *object.Integer:
+		if node.Operator == token.MINUS {
+			if right.Value == math.MinInt64 {
+				return nil, intOverflow("unary -")
+			}
+			return &object.Integer{Value: -right.Value}, nil
+		}
+		return right, nil
+	case *object.Float:
+		if node.Operator == token.MINUS {
+			return &object.Float{Value: -right.Value}, nil
+		}
+		return right, nil
+	}
+
+	return nil, object.NewError(object.TypeError, "bad operand type for unary %s: '%s'", node.Operator.Symbol(), right.Type())
+}
+
+func (in *Interpreter) evalInfix(node *ast.InfixNode, env *object.Environment) (object.Object, error) {
+	left, err := in.Eval(node.Left, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.Operator {
+	case token.AND, token.OR:
+		truthy, err := in.isTruthy(left)
+		if err != nil {
+			return nil, err
+		}
+		if truthy == (node.Operator == token.OR) {
+			return left, nil
+		}
+		return in.Eval(node.Right, env)
+	case token.DOT:
+		name, ok := node.Right.(*ast.IdentifierNode)
+		if !ok {
+			return nil, object.NewError(object.SyntaxError, "invalid attribute name %s", node.Right)
+		}
+		return in.getAttr(left, name.Name)
+	}
+
+	right, err := in.Eval(node.Right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return in.binaryOp(node.Operator, left, right)
+}
+
+func (in *Interpreter) binaryOp(op token.TokenType, left, right object.Object) (object.Object, error) {
+	switch op {
+	case token.EQ, token.NE:
+		eq, err := in.equals(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return object.NativeBool(eq == (op == token.EQ)), nil
+	case token.LT, token.LE, token.GT, token.GE:
+		return in.compareOp(op, left, right)
+	case token.IN, token.NOT_IN:
+		found, err := in.contains(right, left)
+		if err != nil {
+			return nil, err
+		}
+		return object.NativeBool(found == (op == token.IN)), nil
+	}
+
+	if l, r := toNumber(left), toNumber(right); isNumber(l) && isNumber(r) {
+		li, lok := l.(*object.Integer)
+		ri, rok := r.(*object.Integer)
+		if lok && rok {
+			return integerOp(op, li.Value, ri.Value)
+		}
+		return floatOp(op, toFloat(l), toFloat(r))
+	}
+
+	if res := sequenceOp(op, left, right); res != nil {
+		return res, nil
+	}
+
+	return nil, object.NewError(object.TypeError, "unsupported operand type(s) for %s: '%s' and '%s'", op.Symbol(), left.Type(), right.Type())
+}
+
+// intOverflow is raised when the result of an integer operation does not fit
+// in an int64, as there are no big integers.
+func intOverflow(op string) error {
+	return object.NewError(object.OverflowError, "integer result of %s is too large", op)
+}
+
+// mulInt multiplies two integers, reporting whether the product fits.
+func mulInt(l, r int64) (int64, bool) {
+	if l == 0 || r == 0 {
+		return 0, true
+	}
+	p := l * r
+	return p, p/r == l && !(l == math.MinInt64 && r == -1)
+}
+
+func integerOp(op token.TokenType, l, r int64) (object.Object, error) {
+	switch op {
+	case token.PLUS:
+		sum := l + r
+		if (l^sum)&(r^sum) < 0 {
+			return nil, intOverflow(op.Symbol())
+		}
+		return &object.Integer{Value: sum}, nil
+	case token.MINUS:
+		diff := l - r
+		if (l^r)&(l^diff) < 0 {
+			return nil, intOverflow(op.Symbol())
+		}
+		return &object.Integer{Value: diff}, nil
+	case token.STAR:
+		p, ok := mulInt(l, r)
+		if !ok {
+			return nil, intOverflow(op.Symbol())
+		}
+		return &object.Integer{Value: p}, nil
+	case token.SLASH:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "division by zero")
+		}
+		return &object.Float{Value: float64(l) / float64(r)}, nil
+	case token.DSLASH:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "integer division or modulo by zero")
+		}
+		if l == math.MinInt64 && r == -1 {
+			return nil, intOverflow(op.Symbol())
+		}
+		q := l / r
+		if (l%r != 0) && ((l < 0) != (r < 0)) {
+			q--
+		}
+		return &object.Integer{Value: q}, nil
+	case token.PERCENT:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "integer modulo by zero")
+		}
+		m := l % r
+		if m != 0 && ((m < 0) != (r < 0)) {
+			m += r
+		}
+		return &object.Integer{Value: m}, nil
+	case token.DSTAR:
+		if r < 0 {
+			return floatOp(op, float64(l), float64(r))
+		}
+		result, ok := int64(1), true
+		for base := l; r > 0 && ok; r >>= 1 {
+			if r&1 == 1 {
+				result, ok = mulInt(result, base)
+			}
+			if r > 1 && ok {
+				base, ok = mulInt(base, base)
+			}
+		}
+		if !ok {
+			return nil, intOverflow(op.Symbol())
+		}
+		return &object.Integer{Value: result}, nil
+	}
+
+	return nil, object.NewError(object.TypeError, "unsupported operand type(s) for %s: 'int' and 'int'", op.Symbol())
+}
+
+func floatOp(op token.TokenType, l, r float64) (object.Object, error) {
+	switch op {
+	case token.PLUS:
+		return &object.Float{Value: l + r}, nil
+	case token.MINUS:
+		return &object.Float{Value: l - r}, nil
+	case token.STAR:
+		return &object.Float{Value: l * r}, nil
+	case token.SLASH:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "float division by zero")
+		}
+		return &object.Float{Value: l / r}, nil
+	case token.DSLASH:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "float floor division by zero")
+		}
+		return &object.Float{Value: math.Floor(l / r)}, nil
+	case token.PERCENT:
+		if r == 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "float modulo by zero")
+		}
+		m := math.Mod(l, r)
+		if m != 0 && ((m < 0) != (r < 0)) {
+			m += r
+		}
+		return &object.Float{Value: m}, nil
+	case token.DSTAR:
+		if l == 0 && r < 0 {
+			return nil, object.NewError(object.ZeroDivisionError, "zero to a negative power")
+		}
+		return &object.Float{Value: math.Pow(l, r)}, nil
+	}
+
+	return nil, object.NewError(object.TypeError, "unsupported operand type(s) for %s: 'float' and 'float'", op.Symbol())
+}
+
+// sequenceOp handles concatenation and repetition, returning nil when the
+// operands are not a supported combination.
+func sequenceOp(op token.TokenType, left, right object.Object) object.Object {
+	switch op {
+	case token.PLUS:
+		switch l := left.(type) {
+		case *object.String:
+			if r, ok := right.(*object.String); ok {
+				return &object.String{Value: l.Value + r.Value}
+			}
+		case *object.List:
+			if r, ok := right.(*object.List); ok {
+				return &object.List{Elements: concat(l.Elements, r.Elements)}
+			}
+		case *object.Tuple:
+			if r, ok := right.(*object.Tuple); ok {
+				return &object.Tuple{Elements: concat(l.Elements, r.Elements)}
+			}
+		}
+	case token.STAR:
+		if _, ok := toNumber(left).(*object.Integer); ok {
+			left, right = right, left
+		}
+		n, ok := toNumber(right).(*object.Integer)
+		if !ok {
+			return nil
+		}
+		count := int(max(n.Value, 0))
+
+		switch l := left.(type) {
+		case *object.String:
+			return &object.String{Value: strings.Repeat(l.Value, count)}
+		case *object.List:
+			return &object.List{Elements: repeat(l.Elements, count)}
+		case *object.Tuple:
+			return &object.Tuple{Elements: repeat(l.Elements, count)}
+		}
+	}
+
+	return nil
+}
+
+func concat(a, b []object.Object) []object.Object {
+	result := make([]object.Object, 0, len(a)+len(b))
+	return append(append(result, a...), b...)
+}
+
+func repeat(elements []object.Object, count int) []object.Object {
+	result := make([]object.Object, 0, len(elements)*count)
+	for range count {
+		result = append(result, elements...)
+	}
+	return result
+}
+
+func (in *Interpreter) compareOp(op token.TokenType, left, right object.Object) (object.Object, error) {
+	res, err := in.compare(left, right)
+	if err != nil {
+		if exc, ok := err.(*object.Exception); ok && exc.Class == object.TypeError {
+			return nil, object.NewError(object.TypeError, "'%s' not supported between instances of '%s' and '%s'", op.Symbol(), left.Type(), right.Type())
+		}
+		return nil, err
+	}
+
+	switch op {
+	case token.LT:
+		return object.NativeBool(res < 0), nil
+	case token.LE:
+		return object.NativeBool(res <= 0), nil
+	case token.GT:
+		return object.NativeBool(res > 0), nil
+	default:
+		return object.NativeBool(res >= 0), nil
+	}
+}
+
+// compare orders two objects, returning a negative, zero or positive result.
+func (in *Interpreter) compare(left, right object.Object) (int, error) {
+	if l, r := toNumber(left), toNumber(right); isNumber(l) && isNumber(r) {
+		li, lok := l.(*object.Integer)
+		ri, rok := r.(*object.Integer)
+		if lok && rok {
+			return cmp.Compare(li.Value, ri.Value), nil
+		}
+
+		lf, rf := toFloat(l), toFloat(r)
+		switch {
+		case lf < rf:
+			return -1, nil
+		case lf > rf:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	switch l := left.(type) {
+	case *object.String:
+		if r, ok := right.(*object.String); ok {
+			return strings.Compare(l.Value, r.Value), nil
+		}
+	case *object.List:
+		if r, ok := right.(*object.List); ok {
+			return in.compareSequences(l.Elements, r.Elements)
+		}
+	case *object.Tuple:
+		if r, ok := right.(*object.Tuple); ok {
+			return in.compareSequences(l.Elements, r.Elements)
+		}
+	}
+
+	return 0, object.NewError(object.TypeError, "cannot compare '%s' and '%s'", left.Type(), right.Type())
+}
+
+func (in *Interpreter) compareSequences(left, right []object.Object) (int, error) {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		eq, err := in.equals(left[i], right[i])
+		if err != nil {
+			return 0, err
+		}
+		if !eq {
+			return in.compare(left[i], right[i])
+		}
+	}
+	return len(left) - len(right), nil
+}
+
+func (in *Interpreter) equals(left, right object.Object) (bool, error) {
+	if left == right {
+		return true, nil
+	}
+
+	if l, r := toNumber(left), toNumber(right); isNumber(l) && isNumber(r) {
+		res, err := in.compare(l, r)
+		return res == 0, err
+	}
+
+	switch l := left.(type) {
+	case *object.String:
+		if r, ok := right.(*object.String); ok {
+			return l.Value == r.Value, nil
+		}
+	case *object.List:
+		if r, ok := right.(*object.List); ok {
+			return in.equalSequences(l.Elements, r.Elements)
+		}
+	case *object.Tuple:
+		if r, ok := right.(*object.Tuple); ok {
+			return in.equalSequences(l.Elements, r.Elements)
+		}
+	case *object.Dict:
+		if r, ok := right.(*object.Dict); ok {
+			return in.equalDicts(l, r)
+		}
+	case *object.Set:
+		if r, ok := right.(*object.Set); ok {
+			if l.Len() != r.Len() {
+				return false, nil
+			}
+			return in.isSubset(l, r)
+		}
+	}
+
+	for _, pair := range [][2]object.Object{{left, right}, {right, left}} {
+		if res, ok, err := in.callSpecial(pair[0], "__eq__", pair[1]); ok {
+			if err != nil {
+				return false, err
+			}
+			return in.isTruthy(res)
+		}
+	}
+
+	return false, nil
+}
+
+func (in *Interpreter) equalDicts(left, right *object.Dict) (bool, error) {
+	if left.Len() != right.Len() {
+		return false, nil
+	}
+	for i := range left.Len() {
+		pair := left.PairAt(i)
+		key, err := in.hashKey(right, pair.Key)
+		if err != nil {
+			return false, err
+		}
+		val, ok := right.Get(key)
+		if !ok {
+			return false, nil
+		}
+		eq, err := in.equals(pair.Value, val)
+		if err != nil || !eq {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (in *Interpreter) equalSequences(left, right []object.Object) (bool, error) {
+	if len(left) != len(right) {
+		return false, nil
+	}
+	for i := range left {
+		eq, err := in.equals(left[i], right[i])
+		if err != nil || !eq {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (in *Interpreter) isTruthy(obj object.Object) (bool, error) {
+	switch obj := obj.(type) {
+	case *object.None:
+		return false, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value != 0, nil
+	case *object.Float:
+		return obj.Value != 0, nil
+	case *object.String:
+		return obj.Value != "", nil
+	case *object.List:
+		return len(obj.Elements) > 0, nil
+	case *object.Tuple:
+		return len(obj.Elements) > 0, nil
+	case *object.Dict:
+		return obj.Len() > 0, nil
+	case *object.DictView:
+		return obj.Dict.Len() > 0, nil
+	case *object.Range:
+		return obj.Len() > 0, nil
+	case *object.Set:
+		return obj.Len() > 0, nil
+	}
+
+	if res, ok, err := in.callSpecial(obj, "__bool__"); ok {
+		if err != nil {
+			return false, err
+		}
+		b, isBool := res.(*object.Boolean)
+		if !isBool {
+			return false, object.NewError(object.TypeError, "__bool__ should return bool, returned %s", res.Type())
+		}
+		return b.Value, nil
+	}
+	if hasSpecial(obj, "__len__") {
+		n, err := in.lengthOf(obj)
+		return n > 0, err
+	}
+	return true, nil
+}
+
+// toNumber converts booleans to integers, since bool is a subclass of int.
+func toNumber(obj object.Object) object.Object {
+	if b, ok := obj.(*object.Boolean); ok {
+		if b.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	}
+	return obj
+}
+
+func isNumber(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.Float:
+		return true
+	}
+	return false
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
+func (in *Interpreter) evalSubscript(node *ast.SliceNode, env *object.Environment) (object.Object, error) {
+	left, err := in.Eval(node.Left, env)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := in.Eval(node.Index, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return in.getItem(left, index)
+}
+
+func (in *Interpreter) evalSliceExpr(node *ast.SliceExprNode, env *object.Environment) (object.Object, error) {
+	s := &object.Slice{Start: object.NONE, Stop: object.NONE, Step: object.NONE}
+	for _, part := range []struct {
+		node ast.Node
+		dest *object.Object
+	}{{node.Lower, &s.Start}, {node.Upper, &s.Stop}, {node.Step, &s.Step}} {
+		if part.node == nil {
+			continue
+		}
+		val, err := in.Eval(part.node, env)
+		if err != nil {
+			return nil, err
+		}
+		*part.dest = val
+	}
+	return s, nil
+}
+
+func (in *Interpreter) getItem(container, index object.Object) (object.Object, error) {
+	if s, ok := index.(*object.Slice); ok {
+		if res, ok, err := getSlice(container, s); ok {
+			return res, err
+		}
+	}
+
+	switch c := container.(type) {
+	case *object.List:
+		i, err := sequenceIndex(len(c.Elements), index, "list")
+		if err != nil {
+			return nil, err
+		}
+		return c.Elements[i], nil
+	case *object.Tuple:
+		i, err := sequenceIndex(len(c.Elements), index, "tuple")
+		if err != nil {
+			return nil, err
+		}
+		return c.Elements[i], nil
+	case *object.String:
+		runes := []rune(c.Value)
+		i, err := sequenceIndex(len(runes), index, "string")
+		if err != nil {
+			return nil, err
+		}
+		return &object.String{Value: string(runes[i])}, nil
+	case *object.Range:
+		i, err := sequenceIndex(int(c.Len()), index, "range object")
+		if err != nil {
+			return nil, err
+		}
+		return &object.Integer{Value: c.Item(int64(i))}, nil
+	case *object.Dict:
+		key, err := in.hashKey(c, index)
+		if err != nil {
+			return nil, err
+		}
+		if val, ok := c.Get(key); ok {
+			return val, nil
+		}
+		return nil, &object.Exception{Class: object.KeyError, Args: []object.Object{index}}
+	}
+
+	if res, ok, err := in.callSpecial(container, "__getitem__", index); ok {
+		return res, err
+	}
+	return nil, object.NewError(object.TypeError, "'%s' object is not subscriptable", container.Type())
+}
+
+// getSlice implements slicing of the built-in sequences. ok is false for
+// other containers.
+func getSlice(container object.Object, s *object.Slice) (res object.Object, ok bool, err error) {
+	switch c := container.(type) {
+	case *object.List:
+		indices, err := sliceIndices(s, len(c.Elements))
+		if err != nil {
+			return nil, true, err
+		}
+		return &object.List{Elements: selectItems(c.Elements, indices)}, true, nil
+	case *object.Tuple:
+		indices, err := sliceIndices(s, len(c.Elements))
+		if err != nil {
+			return nil, true, err
+		}
+		return &object.Tuple{Elements: selectItems(c.Elements, indices)}, true, nil
+	case *object.String:
+		runes := []rune(c.Value)
+		indices, err := sliceIndices(s, len(runes))
+		if err != nil {
+			return nil, true, err
+		}
+		return &object.String{Value: string(selectItems(runes, indices))}, true, nil
+	case *object.Range:
+		start, stop, step, err := sliceBounds(s, int(c.Len()))
+		if err != nil {
+			return nil, true, err
+		}
+		return &object.Range{Start: c.Item(int64(start)), Stop: c.Item(int64(stop)), Step: c.Step * int64(step)}, true, nil
+	}
+	return nil, false, nil
+}
+
+func selectItems[T any](items []T, indices []int) []T {
+	res := make([]T, len(indices))
+	for i, index := range indices {
+		res[i] = items[index]
+	}
+	return res
+}
+
+// sliceBounds resolves a slice against a sequence of the given length the
+// way slice.indices does, clamping out of range bounds.
+func sliceBounds(s *object.Slice, length int) (start, stop, step int, err error) {
+	step = 1
+	if s.Step != object.NONE {
+		if step, err = sliceIndex(s.Step); err != nil {
+			return 0, 0, 0, err
+		}
+		if step == 0 {
+			return 0, 0, 0, object.NewError(object.ValueError, "slice step cannot be zero")
+		}
+	}
+
+	lower, upper := 0, length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+
+	bound := func(obj object.Object, def int) (int, error) {
+		if obj == object.NONE {
+			return def, nil
+		}
+		i, err := sliceIndex(obj)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return max(i+length, lower), nil
+		}
+		return min(i, upper), nil
+	}
+
+	startDefault, stopDefault := lower, upper
+	if step < 0 {
+		startDefault, stopDefault = upper, lower
+	}
+	if start, err = bound(s.Start, startDefault); err != nil {
+		return 0, 0, 0, err
+	}
+	if stop, err = bound(s.Stop, stopDefault); err != nil {
+		return 0, 0, 0, err
+	}
+	return start, stop, step, nil
+}
+
+func sliceIndex(obj object.Object) (int, error) {
+	i, ok := toNumber(obj).(*object.Integer)
+	if !ok {
+		return 0, object.NewError(object.TypeError, "slice indices must be integers or None or have an __index__ method")
+	}
+	return int(i.Value), nil
+}
+
+// sliceIndices returns the indices a slice selects from a sequence of the given length.
+func sliceIndices(s *object.Slice, length int) ([]int, error) {
+	start, stop, step, err := sliceBounds(s, length)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := []int{}
+	for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
+func (in *Interpreter) setItem(container, index, value object.Object) error {
+	switch c := container.(type) {
+	case *object.List:
+		if s, ok := index.(*object.Slice); ok {
+			return in.setSlice(c, s, value)
+		}
+		i, err := sequenceIndex(len(c.Elements), index, "list")
+		if err != nil {
+			return err
+		}
+		c.Elements[i] = value
+		return nil
+	case *object.Dict:
+		return in.dictSet(c, index, value)
+	}
+
+	if _, ok, err := in.callSpecial(container, "__setitem__", index, value); ok {
+		return err
+	}
+	return object.NewError(object.TypeError, "'%s' object does not support item assignment", container.Type())
+}
+
+// setSlice assigns the items of value to a slice of l. A simple slice may
+// change the length of the list; an extended one must match it exactly.
+func (in *Interpreter) setSlice(l *object.List, s *object.Slice, value object.Object) error {
+	it, err := in.lookupIter(value)
+	if err != nil {
+		return err
+	}
+	if it == nil {
+		return object.NewError(object.TypeError, "must assign iterable to slice")
+	}
+	items, err := in.collect(it)
+	if err != nil {
+		return err
+	}
+
+	start, stop, step, err := sliceBounds(s, len(l.Elements))
+	if err != nil {
+		return err
+	}
+	if step == 1 {
+		l.Elements = slices.Concat(l.Elements[:start], items, l.Elements[max(start, stop):])
+		return nil
+	}
+
+	indices, err := sliceIndices(s, len(l.Elements))
+	if err != nil {
+		return err
+	}
+	if len(items) != len(indices) {
+		return object.NewError(object.ValueError, "attempt to assign sequence of size %d to extended slice of size %d", len(items), len(indices))
+	}
+	for i, index := range indices {
+		l.Elements[index] = items[i]
+	}
+	return nil
+}
+
+func (in *Interpreter) delItem(container, index object.Object) error {
+	switch c := container.(type) {
+	case *object.List:
+		if s, ok := index.(*object.Slice); ok {
+			indices, err := sliceIndices(s, len(c.Elements))
+			if err != nil {
+				return err
+			}
+			removed := map[int]bool{}
+			for _, i := range indices {
+				removed[i] = true
+			}
+			elements := []object.Object{}
+			for i, elem := range c.Elements {
+				if !removed[i] {
+					elements = append(elements, elem)
+				}
+			}
+			c.Elements = elements
+			return nil
+		}
+		i, err := sequenceIndex(len(c.Elements), index, "list")
+		if err != nil {
+			return err
+		}
+		c.Elements = slices.Delete(c.Elements, i, i+1)
+		return nil
+	case *object.Dict:
+		key, err := in.hashKey(c, index)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.Delete(key); !ok {
+			return &object.Exception{Class: object.KeyError, Args: []object.Object{index}}
+		}
+		return nil
+	}
+
+	if _, ok, err := in.callSpecial(container, "__delitem__", index); ok {
+		return err
+	}
+	return object.NewError(object.TypeError, "'%s' object doesn't support item deletion", container.Type())
+}
+
+func sequenceIndex(length int, index object.Object, kind string) (int, error) {
+	i, ok := toNumber(index).(*object.Integer)
+	if !ok {
+		return 0, object.NewError(object.TypeError, "%s indices must be integers, not %s", kind, index.Type())
+	}
+
+	n := int(i.Value)
+	if n < 0 {
+		n += length
+	}
+	if n < 0 || n >= length {
+		return 0, object.NewError(object.IndexError, "%s index out of range", kind)
+	}
+	return n, nil
+}
+
+// hashTable is a dict or set.
+type hashTable interface {
+	Hashed(hash int64) []object.Object
+}
+
+// hashKey returns the key of obj in t, raising TypeError if it is unhashable.
+func (in *Interpreter) hashKey(t hashTable, obj object.Object) (object.HashKey, error) {
+	key, _, _, err := in.findKey(t, obj)
+	return key, err
+}
+
+// findKey is hashKey that also returns the result of obj's __hash__, if its
+// class defines one. Such an object takes the key of an equal one with the
+// same hash in t.
+func (in *Interpreter) findKey(t hashTable, obj object.Object) (key object.HashKey, hash int64, hashed bool, err error) {
+	key, ok := object.Hash(obj)
+	if !ok {
+		return key, 0, false, object.NewError(object.TypeError, "unhashable type: '%s'", obj.Type())
+	}
+
+	hash, hashed, err = in.userHash(obj)
+	if err != nil || !hashed {
+		return key, hash, hashed, err
+	}
+	for _, other := range t.Hashed(hash) {
+		eq, err := in.equals(other, obj)
+		if err != nil {
+			return key, hash, hashed, err
+		}
+		if eq {
+			key, _ = object.Hash(other)
+			break
+		}
+	}
+	return key, hash, hashed, nil
+}
+
+// userHash calls the __hash__ of an instance whose class defines one, or of
+// those in a tuple. Setting __hash__ to None makes instances unhashable.
+func (in *Interpreter) userHash(obj object.Object) (int64, bool, error) {
+	if t, ok := obj.(*object.Tuple); ok {
+		return in.tupleHash(t)
+	}
+
+	fn, ok := specialAttr(obj, "__hash__")
+	if !ok {
+		return 0, false, nil
+	}
+	if fn == object.NONE {
+		return 0, false, object.NewError(object.TypeError, "unhashable type: '%s'", obj.Type())
+	}
+
+	res, _, err := in.callSpecial(obj, "__hash__")
+	if err != nil {
+		return 0, false, err
+	}
+	i, ok := toNumber(res).(*object.Integer)
+	if !ok {
+		return 0, false, object.NewError(object.TypeError, "__hash__ method should return an integer")
+	}
+	return i.Value, true, nil
+}
+
+// tupleHash combines the hashes of the elements of a tuple that holds
+// instances with __hash__, so that equal tuples find each other.
+func (in *Interpreter) tupleHash(t *object.Tuple) (int64, bool, error) {
+	hash, hashed := int64(len(t.Elements)), false
+	for _, elem := range t.Elements {
+		h, ok, err := in.userHash(elem)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			hashed = true
+		} else {
+			key, _ := object.Hash(elem)
+			f := fnv.New64a()
+			f.Write([]byte(string(key.Type) + ":" + key.Value))
+			h = int64(f.Sum64())
+		}
+		hash = hash*1000003 ^ h
+	}
+	return hash, hashed, nil
+}
+
+func (in *Interpreter) dictSet(d *object.Dict, key, value object.Object) error {
+	k, hash, hashed, err := in.findKey(d, key)
+	if err != nil {
+		return err
+	}
+	if hashed {
+		d.SetHashed(k, hash, key, value)
+	} else {
+		d.Set(k, key, value)
+	}
+	return nil
+}
+
+func (in *Interpreter) setAdd(s *object.Set, elem object.Object) error {
+	key, hash, hashed, err := in.findKey(s, elem)
+	if err != nil {
+		return err
+	}
+	if hashed {
+		s.AddHashed(key, hash, elem)
+	} else {
+		s.Add(key, elem)
+	}
+	return nil
+}
+
+func (in *Interpreter) getAttr(obj object.Object, name string) (object.Object, error) {
+	if name == "__class__" {
+		return classOf(obj), nil
+	}
+
+	switch obj := obj.(type) {
+	case *object.Class:
+		if name == "__name__" {
+			return &object.String{Value: obj.Name}, nil
+		}
+		if val, ok := obj.LookupAttr(name); ok {
+			return bindClassAttr(obj, val), nil
+		}
+		return nil, object.NewError(object.AttributeError, "type object '%s' has no attribute '%s'", obj.Name, name)
+	case *object.Module:
+		if val, ok := obj.Env.Get(name); ok {
+			return val, nil
+		}
+		return nil, object.NewError(object.AttributeError, "module '%s' has no attribute '%s'", obj.Name, name)
+	case *object.Instance:
+		if val, ok := obj.Attrs[name]; ok {
+			return val, nil
+		}
+	case *object.Exception:
+		if name == "args" {
+			return &object.Tuple{Elements: obj.Args}, nil
+		}
+		if name == "value" && obj.Class.IsSubclass(object.StopIteration) {
+			return stopValue(obj), nil
+		}
+		switch name {
+		case "__cause__":
+			return exceptionOrNone(obj.Cause), nil
+		case "__context__":
+			return exceptionOrNone(obj.Context), nil
+		case "__suppress_context__":
+			return object.NativeBool(obj.SuppressContext), nil
+		}
+		if val, ok := obj.Attrs[name]; ok {
+			return val, nil
+		}
+	case *object.Function:
+		if name == "__name__" {
+			return &object.String{Value: obj.Name}, nil
+		}
+		if val, ok := obj.Attrs[name]; ok {
+			return val, nil
+		}
+		if name == "__doc__" {
+			return object.NONE, nil
+		}
+	case *object.Builtin:
+		if name == "__name__" {
+			return &object.String{Value: obj.Name}, nil
+		}
+	case *object.Property:
+		if val, ok := propertyAttr(obj, name); ok {
+			return val, nil
+		}
+	case *object.StaticMethod:
+		if name == "__func__" {
+			return obj.Function, nil
+		}
+	case *object.ClassMethod:
+		if name == "__func__" {
+			return obj.Function, nil
+		}
+	case *object.Slice:
+		switch name {
+		case "start":
+			return obj.Start, nil
+		case "stop":
+			return obj.Stop, nil
+		case "step":
+			return obj.Step, nil
+		}
+	}
+
+	if val, ok := classOf(obj).LookupAttr(name); ok {
+		return in.bindAttr(obj, val)
+	}
+
+	return nil, object.NewError(object.AttributeError, "'%s' object has no attribute '%s'", obj.Type(), name)
+}
+
+func (in *Interpreter) setAttr(obj object.Object, name string, value object.Object) error {
+	switch obj := obj.(type) {
+	case *object.Class:
+		if isBuiltinClass(obj) {
+			return object.NewError(object.TypeError, "cannot set '%s' attribute of immutable type '%s'", name, obj.Name)
+		}
+		obj.Attrs[name] = value
+		return nil
+	case *object.Module:
+		obj.Env.Set(name, value)
+		return nil
+	case *object.Instance:
+		return in.setInstanceAttr(obj, obj.Class, obj.Attrs, name, value)
+	case *object.Exception:
+		if obj.Attrs == nil {
+			obj.Attrs = map[string]object.Object{}
+		}
+		return in.setInstanceAttr(obj, obj.Class, obj.Attrs, name, value)
+	case *object.Function:
+		if name == "__name__" {
+			s, ok := value.(*object.String)
+			if !ok {
+				return object.NewError(object.TypeError, "__name__ must be set to a string object")
+			}
+			obj.Name = s.Value
+			return nil
+		}
+		obj.Attrs[name] = value
+		return nil
+	}
+
+	return object.NewError(object.AttributeError, "'%s' object has no attribute '%s'", obj.Type(), name)
+}
+
+func (in *Interpreter) delAttr(obj object.Object, name string) error {
+	switch obj := obj.(type) {
+	case *object.Class:
+		if isBuiltinClass(obj) {
+			return object.NewError(object.TypeError, "cannot delete '%s' attribute of immutable type '%s'", name, obj.Name)
+		}
+		if _, ok := obj.Attrs[name]; ok {
+			delete(obj.Attrs, name)
+			return nil
+		}
+		return object.NewError(object.AttributeError, "type object '%s' has no attribute '%s'", obj.Name, name)
+	case *object.Module:
+		if obj.Env.Delete(name) {
+			return nil
+		}
+		return object.NewError(object.AttributeError, "module '%s' has no attribute '%s'", obj.Name, name)
+	case *object.Instance:
+		return in.delInstanceAttr(obj, obj.Class, obj.Attrs, name)
+	case *object.Exception:
+		return in.delInstanceAttr(obj, obj.Class, obj.Attrs, name)
+	case *object.Function:
+		if _, ok := obj.Attrs[name]; ok {
+			delete(obj.Attrs, name)
+			return nil
+		}
+	}
+
+	return object.NewError(object.AttributeError, "'%s' object has no attribute '%s'", obj.Type(), name)
+}
+
+func exceptionOrNone(exc *object.Exception) object.Object {
+	if exc == nil {
+		return object.NONE
+	}
+	return exc
+}
@@ -0,0 +1,857 @@
+package evaluator
+
+import (
+	"slices"
+	"snek/object"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	typeClass     = object.NewClass("type", object.ObjectClass)
+	noneClass     = object.NewClass("NoneType", object.ObjectClass)
+	intClass      = object.NewClass("int", object.ObjectClass)
+	boolClass     = object.NewClass("bool", intClass)
+	floatClass    = object.NewClass("float", object.ObjectClass)
+	strClass      = object.NewClass("str", object.ObjectClass)
+	tupleClass    = object.NewClass("tuple", object.ObjectClass)
+	listClass     = object.NewClass("list", object.ObjectClass)
+	dictClass     = object.NewClass("dict", object.ObjectClass)
+	setClass      = object.NewClass("set", object.ObjectClass)
+	rangeClass    = object.NewClass("range", object.ObjectClass)
+	functionClass = object.NewClass("function", object.ObjectClass)
+	builtinClass  = object.NewClass("builtin_function_or_method", object.ObjectClass)
+	methodClass   = object.NewClass("method", object.ObjectClass)
+	moduleClass   = object.NewClass("module", object.ObjectClass)
+	sliceClass    = object.NewClass("slice", object.ObjectClass)
+
+	staticmethodClass = object.NewClass("staticmethod", object.ObjectClass)
+	classmethodClass  = object.NewClass("classmethod", object.ObjectClass)
+	propertyClass     = object.NewClass("property", object.ObjectClass)
+
+	// iteratorClass is the shared base giving built-in iterators __iter__ and __next__.
+	iteratorClass  = object.NewClass("iterator", object.ObjectClass)
+	enumerateClass = object.NewClass("enumerate", iteratorClass)
+	zipClass       = object.NewClass("zip", iteratorClass)
+	mapClass       = object.NewClass("map", iteratorClass)
+	filterClass    = object.NewClass("filter", iteratorClass)
+	reversedClass  = object.NewClass("reversed", iteratorClass)
+	generatorClass = object.NewClass("generator", iteratorClass)
+)
+
+var typeClasses = map[object.ObjectType]*object.Class{}
+
+// isBuiltinClass reports whether cls is one of the built-in classes, which are
+// shared by every interpreter and so can't be changed.
+func isBuiltinClass(cls *object.Class) bool {
+	return typeClasses[object.ObjectType(cls.Name)] == cls || builtins[cls.Name] == cls
+}
+
+func init() {
+	for _, cls := range []*object.Class{
+		typeClass, noneClass, intClass, boolClass, floatClass, strClass, tupleClass, listClass, dictClass, setClass,
+		rangeClass, functionClass, builtinClass, methodClass, moduleClass, enumerateClass, zipClass, mapClass, filterClass, reversedClass,
+		generatorClass, staticmethodClass, classmethodClass, propertyClass, sliceClass,
+	} {
+		typeClasses[object.ObjectType(cls.Name)] = cls
+	}
+
+	for _, name := range []string{
+		"list_iterator", "tuple_iterator", "str_iterator", "range_iterator", "set_iterator",
+		"dict_keyiterator", "dict_valueiterator", "dict_itemiterator",
+	} {
+		typeClasses[object.ObjectType(name)] = object.NewClass(name, iteratorClass)
+	}
+
+	for _, name := range []string{"dict_keys", "dict_values", "dict_items"} {
+		typeClasses[object.ObjectType(name)] = object.NewClass(name, object.ObjectClass)
+	}
+
+	initIteratorMethods()
+	initGeneratorMethods()
+	initListMethods()
+	initTupleMethods()
+	initDictMethods()
+	initSetMethods()
+	initStrMethods()
+	initPropertyMethods()
+}
+
+func classOf(obj object.Object) *object.Class {
+	switch obj := obj.(type) {
+	case *object.Exception:
+		return obj.Class
+	case *object.Instance:
+		return obj.Class
+	}
+
+	if cls, ok := typeClasses[obj.Type()]; ok {
+		return cls
+	}
+	if _, ok := obj.(object.Iterator); ok {
+		return iteratorClass
+	}
+	return object.ObjectClass
+}
+
+func isInstance(obj object.Object, cls *object.Class) bool {
+	return classOf(obj).IsSubclass(cls)
+}
+
+// method registers a built-in method whose receiver must be a T.
+func method[T object.Object](cls *object.Class, name string, fn func(in *Interpreter, self T, args []object.Object, kwargs []object.Keyword) (object.Object, error)) {
+	cls.Attrs[name] = &object.Builtin{Name: name, Fn: builtin(func(in *Interpreter, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if len(args) > 0 {
+			if self, ok := args[0].(T); ok {
+				return fn(in, self, args[1:], kwargs)
+			}
+		}
+		return nil, object.NewError(object.TypeError, "descriptor '%s' for '%s' objects needs an argument of that type", name, cls.Name)
+	})}
+}
+
+func initIteratorMethods() {
+	method(iteratorClass, "__iter__", func(in *Interpreter, self object.Iterator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return self, nil
+	})
+
+	method(iteratorClass, "__next__", func(in *Interpreter, self object.Iterator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		item, err := self.Next()
+		if err == nil && item == nil {
+			return nil, &object.Exception{Class: object.StopIteration}
+		}
+		return item, err
+	})
+}
+
+func initGeneratorMethods() {
+	method(generatorClass, "__next__", func(in *Interpreter, self *object.Generator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return self.Resume(object.NONE, nil)
+	})
+
+	method(generatorClass, "send", func(in *Interpreter, self *object.Generator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("send", args, kwargs, 1, "value")
+		if err != nil {
+			return nil, err
+		}
+		return self.Resume(bound[0], nil)
+	})
+
+	method(generatorClass, "throw", func(in *Interpreter, self *object.Generator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("throw", args, kwargs, 1, "type", "value")
+		if err != nil {
+			return nil, err
+		}
+		exc, err := in.makeException(bound[0], bound[1])
+		if err != nil {
+			return nil, err
+		}
+		return self.Resume(nil, exc)
+	})
+
+	method(generatorClass, "close", func(in *Interpreter, self *object.Generator, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("close", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return object.NONE, self.Close()
+	})
+}
+
+// makeException builds the exception raised by throw(type[, value]), where
+// type may be an exception class or instance.
+func (in *Interpreter) makeException(typ, value object.Object) (*object.Exception, error) {
+	switch typ := typ.(type) {
+	case *object.Exception:
+		if value != nil && value != object.NONE {
+			return nil, object.NewError(object.TypeError, "instance exception may not have a separate value")
+		}
+		return typ, nil
+	case *object.Class:
+		if !typ.IsSubclass(object.BaseExceptionClass) {
+			break
+		}
+		if exc, ok := value.(*object.Exception); ok && exc.Class.IsSubclass(typ) {
+			return exc, nil
+		}
+
+		args := []object.Object{}
+		if value != nil && value != object.NONE {
+			args = append(args, value)
+		}
+		res, err := in.callObject(typ, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if exc, ok := res.(*object.Exception); ok {
+			return exc, nil
+		}
+	}
+
+	return nil, object.NewError(object.TypeError, "exceptions must be classes or instances deriving from BaseException, not %s", typ.Type())
+}
+
+func initPropertyMethods() {
+	accessor := func(name string, set func(p *object.Property, fn object.Object)) {
+		method(propertyClass, name, func(in *Interpreter, self *object.Property, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			bound, err := bindArgs(name, args, kwargs, 1, "func")
+			if err != nil {
+				return nil, err
+			}
+			p := *self
+			set(&p, bound[0])
+			return &p, nil
+		})
+	}
+
+	accessor("getter", func(p *object.Property, fn object.Object) { p.Get = fn })
+	accessor("setter", func(p *object.Property, fn object.Object) { p.Set = fn })
+	accessor("deleter", func(p *object.Property, fn object.Object) { p.Delete = fn })
+}
+
+func initListMethods() {
+	method(listClass, "append", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("append", args, kwargs, 1, "object")
+		if err != nil {
+			return nil, err
+		}
+		self.Elements = append(self.Elements, bound[0])
+		return object.NONE, nil
+	})
+
+	method(listClass, "extend", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("extend", args, kwargs, 1, "iterable")
+		if err != nil {
+			return nil, err
+		}
+		items, err := in.collect(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		self.Elements = append(self.Elements, items...)
+		return object.NONE, nil
+	})
+
+	method(listClass, "insert", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("insert", args, kwargs, 2, "index", "object")
+		if err != nil {
+			return nil, err
+		}
+		i, err := toInt(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		n := int64(len(self.Elements))
+		if i < 0 {
+			i = max(i+n, 0)
+		}
+		self.Elements = slices.Insert(self.Elements, int(min(i, n)), bound[1])
+		return object.NONE, nil
+	})
+
+	method(listClass, "pop", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("pop", args, kwargs, 0, "index")
+		if err != nil {
+			return nil, err
+		}
+		if len(self.Elements) == 0 {
+			return nil, object.NewError(object.IndexError, "pop from empty list")
+		}
+		index := object.Object(&object.Integer{Value: -1})
+		if bound[0] != nil {
+			index = bound[0]
+		}
+		i, err := sequenceIndex(len(self.Elements), index, "pop")
+		if err != nil {
+			return nil, err
+		}
+		item := self.Elements[i]
+		self.Elements = slices.Delete(self.Elements, i, i+1)
+		return item, nil
+	})
+
+	method(listClass, "remove", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("remove", args, kwargs, 1, "value")
+		if err != nil {
+			return nil, err
+		}
+		i, err := in.indexOf(self.Elements, bound[0])
+		if err != nil {
+			return nil, err
+		} else if i < 0 {
+			return nil, object.NewError(object.ValueError, "list.remove(x): x not in list")
+		}
+		self.Elements = slices.Delete(self.Elements, i, i+1)
+		return object.NONE, nil
+	})
+
+	method(listClass, "index", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return in.sequenceIndexOf("list", self.Elements, args, kwargs)
+	})
+
+	method(listClass, "count", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return in.sequenceCount(self.Elements, args, kwargs)
+	})
+
+	method(listClass, "clear", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("clear", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		self.Elements = []object.Object{}
+		return object.NONE, nil
+	})
+
+	method(listClass, "copy", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("copy", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return &object.List{Elements: slices.Clone(self.Elements)}, nil
+	})
+
+	method(listClass, "reverse", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("reverse", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		slices.Reverse(self.Elements)
+		return object.NONE, nil
+	})
+
+	method(listClass, "sort", func(in *Interpreter, self *object.List, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if len(args) > 0 {
+			return nil, object.NewError(object.TypeError, "sort() takes no positional arguments")
+		}
+		bound, err := bindArgs("sort", args, kwargs, 0, "key", "reverse")
+		if err != nil {
+			return nil, err
+		}
+		return object.NONE, in.sortObjects(self.Elements, bound[0], bound[1])
+	})
+}
+
+func initTupleMethods() {
+	method(tupleClass, "index", func(in *Interpreter, self *object.Tuple, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return in.sequenceIndexOf("tuple", self.Elements, args, kwargs)
+	})
+
+	method(tupleClass, "count", func(in *Interpreter, self *object.Tuple, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		return in.sequenceCount(self.Elements, args, kwargs)
+	})
+}
+
+func (in *Interpreter) indexOf(elements []object.Object, value object.Object) (int, error) {
+	for i, elem := range elements {
+		eq, err := in.equals(elem, value)
+		if err != nil {
+			return -1, err
+		}
+		if eq {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (in *Interpreter) sequenceIndexOf(kind string, elements []object.Object, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("index", args, kwargs, 1, "value")
+	if err != nil {
+		return nil, err
+	}
+	i, err := in.indexOf(elements, bound[0])
+	if err != nil {
+		return nil, err
+	} else if i < 0 {
+		return nil, object.NewError(object.ValueError, "%s.index(x): x not in %s", kind, kind)
+	}
+	return &object.Integer{Value: int64(i)}, nil
+}
+
+func (in *Interpreter) sequenceCount(elements []object.Object, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+	bound, err := bindArgs("count", args, kwargs, 1, "value")
+	if err != nil {
+		return nil, err
+	}
+	count := int64(0)
+	for _, elem := range elements {
+		eq, err := in.equals(elem, bound[0])
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			count++
+		}
+	}
+	return &object.Integer{Value: count}, nil
+}
+
+func initDictMethods() {
+	views := map[string]object.DictViewKind{"keys": object.DICT_KEYS, "values": object.DICT_VALUES, "items": object.DICT_ITEMS}
+	for name, kind := range views {
+		method(dictClass, name, func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			if _, err := bindArgs(name, args, kwargs, 0); err != nil {
+				return nil, err
+			}
+			return &object.DictView{Dict: self, Kind: kind}, nil
+		})
+	}
+
+	method(dictClass, "get", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("get", args, kwargs, 1, "key", "default")
+		if err != nil {
+			return nil, err
+		}
+		key, err := in.hashKey(self, bound[0])
+		if err != nil {
+			return nil, err
+		}
+		if val, ok := self.Get(key); ok {
+			return val, nil
+		} else if bound[1] != nil {
+			return bound[1], nil
+		}
+		return object.NONE, nil
+	})
+
+	method(dictClass, "pop", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("pop", args, kwargs, 1, "key", "default")
+		if err != nil {
+			return nil, err
+		}
+		key, err := in.hashKey(self, bound[0])
+		if err != nil {
+			return nil, err
+		}
+		if pair, ok := self.Delete(key); ok {
+			return pair.Value, nil
+		} else if bound[1] != nil {
+			return bound[1], nil
+		}
+		return nil, &object.Exception{Class: object.KeyError, Args: []object.Object{bound[0]}}
+	})
+
+	method(dictClass, "setdefault", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("setdefault", args, kwargs, 1, "key", "default")
+		if err != nil {
+			return nil, err
+		}
+		key, err := in.hashKey(self, bound[0])
+		if err != nil {
+			return nil, err
+		}
+		if val, ok := self.Get(key); ok {
+			return val, nil
+		}
+		if bound[1] == nil {
+			bound[1] = object.NONE
+		}
+		return bound[1], in.dictSet(self, bound[0], bound[1])
+	})
+
+	method(dictClass, "update", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("update", args, nil, 0, "other")
+		if err != nil {
+			return nil, err
+		}
+		return object.NONE, in.updateDict(self, bound[0], kwargs)
+	})
+
+	method(dictClass, "clear", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("clear", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		self.Clear()
+		return object.NONE, nil
+	})
+
+	method(dictClass, "copy", func(in *Interpreter, self *object.Dict, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("copy", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		d := object.NewDict()
+		return d, in.updateDict(d, self, nil)
+	})
+}
+
+// updateDict merges a mapping or an iterable of key/value pairs, then keyword
+// arguments, into d.
+func (in *Interpreter) updateDict(d *object.Dict, other object.Object, kwargs []object.Keyword) error {
+	if src, ok := other.(*object.Dict); ok {
+		for i := range src.Len() {
+			pair := src.PairAt(i)
+			if err := in.dictSet(d, pair.Key, pair.Value); err != nil {
+				return err
+			}
+		}
+	} else if other != nil {
+		items, err := in.collect(other)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			pair, err := in.collect(item)
+			if err != nil {
+				return err
+			}
+			if len(pair) != 2 {
+				return object.NewError(object.ValueError, "dictionary update sequence element #%d has length %d; 2 is required", i, len(pair))
+			}
+			if err := in.dictSet(d, pair[0], pair[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, kw := range kwargs {
+		d.Set(object.HashKey{Type: object.STR_OBJ, Value: kw.Name}, &object.String{Value: kw.Name}, kw.Value)
+	}
+	return nil
+}
+
+func initSetMethods() {
+	method(setClass, "add", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("add", args, kwargs, 1, "elem")
+		if err != nil {
+			return nil, err
+		}
+		return object.NONE, in.setAdd(self, bound[0])
+	})
+
+	for _, name := range []string{"remove", "discard"} {
+		method(setClass, name, func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			bound, err := bindArgs(name, args, kwargs, 1, "elem")
+			if err != nil {
+				return nil, err
+			}
+			key, err := in.hashKey(self, bound[0])
+			if err != nil {
+				return nil, err
+			}
+			if !self.Remove(key) && name == "remove" {
+				return nil, &object.Exception{Class: object.KeyError, Args: []object.Object{bound[0]}}
+			}
+			return object.NONE, nil
+		})
+	}
+
+	method(setClass, "pop", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("pop", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		if self.Len() == 0 {
+			return nil, object.NewError(object.KeyError, "pop from an empty set")
+		}
+		elem := self.Item(0)
+		key, _ := object.Hash(elem)
+		self.Remove(key)
+		return elem, nil
+	})
+
+	method(setClass, "clear", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("clear", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		self.Clear()
+		return object.NONE, nil
+	})
+
+	method(setClass, "copy", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if _, err := bindArgs("copy", args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return self.Copy(), nil
+	})
+
+	method(setClass, "update", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if len(kwargs) > 0 {
+			return nil, object.NewError(object.TypeError, "set.update() takes no keyword arguments")
+		}
+		for _, other := range args {
+			if err := in.updateSet(self, other); err != nil {
+				return nil, err
+			}
+		}
+		return object.NONE, nil
+	})
+
+	method(setClass, "union", func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		if len(kwargs) > 0 {
+			return nil, object.NewError(object.TypeError, "set.union() takes no keyword arguments")
+		}
+		result := self.Copy()
+		for _, other := range args {
+			if err := in.updateSet(result, other); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	})
+
+	// intersection and difference keep the elements of self that are, or are
+	// not, in every other iterable.
+	for _, name := range []string{"intersection", "difference"} {
+		method(setClass, name, func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			if len(kwargs) > 0 {
+				return nil, object.NewError(object.TypeError, "set.%s() takes no keyword arguments", name)
+			}
+			result := self.Copy()
+			for _, arg := range args {
+				other := object.NewSet()
+				if err := in.updateSet(other, arg); err != nil {
+					return nil, err
+				}
+				for _, elem := range result.Elements() {
+					key, err := in.hashKey(other, elem)
+					if err != nil {
+						return nil, err
+					}
+					if other.Contains(key) != (name == "intersection") {
+						key, _ = object.Hash(elem)
+						result.Remove(key)
+					}
+				}
+			}
+			return result, nil
+		})
+	}
+
+	for _, name := range []string{"issubset", "issuperset"} {
+		method(setClass, name, func(in *Interpreter, self *object.Set, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			bound, err := bindArgs(name, args, kwargs, 1, "other")
+			if err != nil {
+				return nil, err
+			}
+			other := object.NewSet()
+			if err := in.updateSet(other, bound[0]); err != nil {
+				return nil, err
+			}
+			sub, super := self, other
+			if name == "issuperset" {
+				sub, super = other, self
+			}
+			res, err := in.isSubset(sub, super)
+			return object.NativeBool(res), err
+		})
+	}
+}
+
+func (in *Interpreter) updateSet(s *object.Set, other object.Object) error {
+	items, err := in.collect(other)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := in.setAdd(s, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *Interpreter) isSubset(sub, super *object.Set) (bool, error) {
+	for _, elem := range sub.Elements() {
+		key, err := in.hashKey(super, elem)
+		if err != nil || !super.Contains(key) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func initStrMethods() {
+	method(strClass, "join", func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("join", args, kwargs, 1, "iterable")
+		if err != nil {
+			return nil, err
+		}
+		items, err := in.collect(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(items))
+		for i, item := range items {
+			s, ok := item.(*object.String)
+			if !ok {
+				return nil, object.NewError(object.TypeError, "sequence item %d: expected str instance, %s found", i, item.Type())
+			}
+			parts[i] = s.Value
+		}
+		return &object.String{Value: strings.Join(parts, self.Value)}, nil
+	})
+
+	method(strClass, "split", func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("split", args, kwargs, 0, "sep", "maxsplit")
+		if err != nil {
+			return nil, err
+		}
+		maxsplit := int64(-1)
+		if bound[1] != nil {
+			if maxsplit, err = toInt(bound[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		var parts []string
+		if bound[0] == nil || bound[0] == object.NONE {
+			parts = splitWhitespace(self.Value, int(maxsplit))
+		} else {
+			sep, err := toStr(bound[0])
+			if err != nil {
+				return nil, err
+			}
+			if sep == "" {
+				return nil, object.NewError(object.ValueError, "empty separator")
+			}
+			n := -1
+			if maxsplit >= 0 {
+				n = int(maxsplit) + 1
+			}
+			parts = strings.SplitN(self.Value, sep, n)
+		}
+
+		list := &object.List{Elements: make([]object.Object, len(parts))}
+		for i, part := range parts {
+			list.Elements[i] = &object.String{Value: part}
+		}
+		return list, nil
+	})
+
+	strips := map[string]func(string, string) string{"strip": strings.Trim, "lstrip": strings.TrimLeft, "rstrip": strings.TrimRight}
+	for name, strip := range strips {
+		method(strClass, name, func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			bound, err := bindArgs(name, args, kwargs, 0, "chars")
+			if err != nil {
+				return nil, err
+			}
+			chars := " \t\n\r\v\f"
+			if bound[0] != nil && bound[0] != object.NONE {
+				if chars, err = toStr(bound[0]); err != nil {
+					return nil, err
+				}
+			}
+			return &object.String{Value: strip(self.Value, chars)}, nil
+		})
+	}
+
+	cases := map[string]func(string) string{"lower": strings.ToLower, "upper": strings.ToUpper}
+	for name, convert := range cases {
+		method(strClass, name, func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			if _, err := bindArgs(name, args, kwargs, 0); err != nil {
+				return nil, err
+			}
+			return &object.String{Value: convert(self.Value)}, nil
+		})
+	}
+
+	affixes := map[string]func(string, string) bool{"startswith": strings.HasPrefix, "endswith": strings.HasSuffix}
+	for name, test := range affixes {
+		method(strClass, name, func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+			bound, err := bindArgs(name, args, kwargs, 1, "affix")
+			if err != nil {
+				return nil, err
+			}
+			candidates := []object.Object{bound[0]}
+			if tuple, ok := bound[0].(*object.Tuple); ok {
+				candidates = tuple.Elements
+			}
+			for _, candidate := range candidates {
+				affix, err := toStr(candidate)
+				if err != nil {
+					return nil, err
+				}
+				if test(self.Value, affix) {
+					return object.TRUE, nil
+				}
+			}
+			return object.FALSE, nil
+		})
+	}
+
+	method(strClass, "replace", func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("replace", args, kwargs, 2, "old", "new", "count")
+		if err != nil {
+			return nil, err
+		}
+		old, err := toStr(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := toStr(bound[1])
+		if err != nil {
+			return nil, err
+		}
+		count := int64(-1)
+		if bound[2] != nil {
+			if count, err = toInt(bound[2]); err != nil {
+				return nil, err
+			}
+		}
+		return &object.String{Value: strings.Replace(self.Value, old, replacement, int(count))}, nil
+	})
+
+	method(strClass, "find", func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("find", args, kwargs, 1, "sub")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := toStr(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		i := strings.Index(self.Value, sub)
+		if i > 0 {
+			i = utf8.RuneCountInString(self.Value[:i])
+		}
+		return &object.Integer{Value: int64(i)}, nil
+	})
+
+	method(strClass, "count", func(in *Interpreter, self *object.String, args []object.Object, kwargs []object.Keyword) (object.Object, error) {
+		bound, err := bindArgs("count", args, kwargs, 1, "sub")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := toStr(bound[0])
+		if err != nil {
+			return nil, err
+		}
+		if sub == "" {
+			return &object.Integer{Value: int64(utf8.RuneCountInString(self.Value) + 1)}, nil
+		}
+		return &object.Integer{Value: int64(strings.Count(self.Value, sub))}, nil
+	})
+}
+
+// splitWhitespace splits on runs of whitespace like str.split() with no separator.
+func splitWhitespace(s string, maxsplit int) []string {
+	if maxsplit < 0 {
+		return strings.Fields(s)
+	}
+
+	parts := []string{}
+	s = strings.TrimLeft(s, " \t\n\r\v\f")
+	for s != "" && len(parts) < maxsplit {
+		i := strings.IndexAny(s, " \t\n\r\v\f")
+		if i < 0 {
+			break
+		}
+		parts = append(parts, s[:i])
+		s = strings.TrimLeft(s[i:], " \t\n\r\v\f")
+	}
+	if s != "" {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+func toStr(obj object.Object) (string, error) {
+	s, ok := obj.(*object.String)
+	if !ok {
+		return "", object.NewError(object.TypeError, "must be str, not %s", obj.Type())
+	}
+	return s.Value, nil
+}
+
+func toInt(obj object.Object) (int64, error) {
+	i, ok := toNumber(obj).(*object.Integer)
+	if !ok {
+		return 0, object.NewError(object.TypeError, "'%s' object cannot be interpreted as an integer", obj.Type())
+	}
+	return i.Value, nil
+}
@@ -1,3 +1,10 @@
 module snek
 
 go 1.24.0
+
+require (
+	golang.org/x/term v0.38.0
+	golang.org/x/text v0.32.0
+)
+
+require golang.org/x/sys v0.39.0 // indirect
@@ -0,0 +1,100 @@
+package lexer
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+const byteOrderMark = "\uFEFF"
+
+var codingPattern = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*([-\w.]+)`)
+
+// decodeSource prepares the input for tokenizing. A UTF-8 byte order mark
+// is skipped, and source declaring another encoding in a PEP 263 coding
+// comment is converted to UTF-8. Positions then refer to the converted text.
+func (l *Lexer) decodeSource() {
+	bom := strings.HasPrefix(l.input, byteOrderMark)
+	if bom {
+		l.skip(byteOrderMark)
+	}
+
+	encoding := sourceEncoding(l.input[l.pos:])
+	switch {
+	case encoding == "utf-8":
+	case bom:
+		l.errorf("SyntaxError", 0, "encoding problem: %s with BOM", encoding)
+		return
+	default:
+		enc, err := ianaindex.IANA.Encoding(encoding)
+		if err != nil || enc == nil {
+			l.errorf("SyntaxError", 0, "unknown encoding: %s", encoding)
+			return
+		}
+		decoded, err := enc.NewDecoder().String(l.input)
+		if err != nil {
+			l.errorf("SyntaxError", 0, "(unicode error) '%s' codec can't decode source", encoding)
+			return
+		}
+		l.source, l.encoding = l.input, enc
+		l.input = decoded
+	}
+
+	for i, r := range l.input {
+		if r == utf8.RuneError && !strings.HasPrefix(l.input[i:], "\uFFFD") {
+			l.errorf("SyntaxError", i, "(unicode error) 'utf-8' codec can't decode byte 0x%02x", l.input[i])
+			return
+		}
+	}
+}
+
+// encodeTrivia converts the raw text and trivia of the tokens back to the
+// declared encoding, so that Untokenize gives back the original bytes. It is
+// an error for the source not to survive the round trip.
+func (l *Lexer) encodeTrivia() {
+	enc := l.encoding.NewEncoder()
+	for i := range l.tokens {
+		tok := &l.tokens[i]
+		for _, text := range []*string{&tok.Leading, &tok.Raw, &tok.Trailing} {
+			encoded, err := enc.String(*text)
+			if err != nil {
+				l.errorf("SyntaxError", tok.Pos, "(unicode error) can't encode source back to its declared encoding")
+				return
+			}
+			*text = encoded
+		}
+	}
+	if Untokenize(l.tokens) != l.source {
+		l.errorf("SyntaxError", 0, "(unicode error) source doesn't round-trip through its declared encoding")
+	}
+}
+
+// sourceEncoding returns the encoding declared in a coding comment on one
+// of the first two lines, normalized the way Python does, or "utf-8".
+func sourceEncoding(src string) string {
+	lines := strings.SplitN(src, "\n", 3)
+	for i, line := range lines[:min(len(lines), 2)] {
+		if m := codingPattern.FindStringSubmatch(line); m != nil {
+			return normalizeEncoding(m[1])
+		}
+		// The declaration may only follow a comment or blank line.
+		if trimmed := strings.TrimLeft(line, " \t\f\r"); i == 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+	}
+	return "utf-8"
+}
+
+func normalizeEncoding(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	switch {
+	case name == "utf8" || name == "utf-8" || strings.HasPrefix(name, "utf-8-"):
+		return "utf-8"
+	case name == "latin1" || name == "latin-1" || name == "iso-latin-1" || name == "iso-8859-1" ||
+		strings.HasPrefix(name, "latin-1-") || strings.HasPrefix(name, "iso-8859-1-") || strings.HasPrefix(name, "iso-latin-1-"):
+		return "iso-8859-1"
+	}
+	return name
+}
@@ -1,85 +1,160 @@
 package lexer
 
 import (
+	"fmt"
 	"regexp"
 	"snek/token"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/unicode/norm"
 )
 
 type Lexer struct {
-	input       string
-	pos         int
-	indentStack []int // Tracks indentation levels
-	startOfLine bool  // Tracks if we're at the start of a line
-	tokens      []token.Token
-	errors      []string
+	input          string
+	pos            int
+	indentStack    []int         // Tracks indentation levels
+	altIndentStack []int         // Indentation levels measured with a tab width of 1
+	startOfLine    bool          // Tracks if we're at the start of a line
+	brackets       []token.Token // Open brackets, innermost last; newlines inside them are ignored
+	continued      bool          // The last thing read was a line continuation
+	tokens         []token.Token
+	errors         []*Error
+
+	tabWidth   int
+	strictTabs bool
+	trivia     bool
+	pending    strings.Builder // Trivia waiting for the next token
+
+	source   string            // The input before decoding
+	encoding encoding.Encoding // The declared encoding, if not UTF-8
+}
+
+// Error is a problem found while tokenizing. Kind names the Python exception
+// it corresponds to: SyntaxError, IndentationError or TabError. Offset is in
+// bytes and Column in characters. Incomplete is set when the input ended too
+// early, inside brackets or after a line continuation, so that more input
+// could still make it valid.
+type Error struct {
+	Kind       string
+	Offset     int
+	Line       int
+	Column     int
+	Msg        string
+	Incomplete bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type Option func(*Lexer)
+
+// WithTabWidth sets the multiple of columns a tab advances indentation to.
+// The default is 8, as in Python.
+func WithTabWidth(n int) Option {
+	return func(l *Lexer) { l.tabWidth = max(n, 1) }
 }
 
-var tokenPatterns = []struct {
+// WithTrivia sets whether tokens keep their raw text and the whitespace,
+// comments and line continuations around them, so that Untokenize can
+// reproduce the input exactly. For source in another encoding, these stay in
+// that encoding while literals are UTF-8.
+func WithTrivia(keep bool) Option {
+	return func(l *Lexer) { l.trivia = keep }
+}
+
+// WithStrictTabs sets whether indentation whose meaning depends on the tab
+// width is reported as a TabError. It is on by default.
+func WithStrictTabs(strict bool) Option {
+	return func(l *Lexer) { l.strictTabs = strict }
+}
+
+type tokenPattern struct {
 	regex *regexp.Regexp
 	tType token.TokenType
-}{
+}
+
+var tokenPatterns = []tokenPattern{
 	{regexp.MustCompile(`^(\d*\.)?\d+\b`), token.NUMBER},
-	{regexp.MustCompile(`^def\b`), token.DEF},
-	{regexp.MustCompile(`^if\b`), token.IF},
-	{regexp.MustCompile(`^else\b`), token.ELSE},
-	{regexp.MustCompile(`^elif\b`), token.ELIF},
-	{regexp.MustCompile(`^for\b`), token.FOR},
-	{regexp.MustCompile(`^(?:in|not\s+in)\b`), token.IN},
-	{regexp.MustCompile(`^while\b`), token.WHILE},
-	{regexp.MustCompile(`^or\b`), token.OR},
-	{regexp.MustCompile(`^and\b`), token.AND},
-	{regexp.MustCompile(`^not\b`), token.NOT},
-	{regexp.MustCompile(`^pass\b`), token.PASS},
-	{regexp.MustCompile(`^break\b`), token.BREAK},
-	{regexp.MustCompile(`^continue\b`), token.CONTINUE},
-	{regexp.MustCompile(`^return\b`), token.RETURN},
-	{regexp.MustCompile(`^global\b`), token.GLOBAL},
-	{regexp.MustCompile(`^import\b`), token.IMPORT},
-	{regexp.MustCompile(`^from\b`), token.FROM},
-	{regexp.MustCompile(`^(==|!=|>=|>|<=|<)`), token.COMPARE},
-	{regexp.MustCompile(`^(=|\+=|-=|\*=|/=|//=|%=)`), token.ASSIGN},
-	{regexp.MustCompile(`^[+-]`), token.SUM},
-	{regexp.MustCompile(`^\*\*`), token.EXP},
-	{regexp.MustCompile(`^//`), token.PRODUCT},
-	{regexp.MustCompile(`^[*/%]`), token.PRODUCT},
-	{regexp.MustCompile(`^[a-zA-Z_]\w*`), token.IDENTIFIER},
+	{identifierPattern, token.IDENTIFIER},
 	{regexp.MustCompile(`^"([^"\\]*(\\.[^"\\]*)*)"`), token.STRING},
 	{regexp.MustCompile(`^'([^'\\]*(\\.[^'\\]*)*)'`), token.STRING},
-	{regexp.MustCompile(`^\s*\\\n`), token.IGNORE},
-	{regexp.MustCompile(`^\n`), token.NEW_LINE},
-	{regexp.MustCompile(`^\s+`), token.IGNORE},
+	{regexp.MustCompile(`^\\\r?\n`), token.IGNORE},
+	{regexp.MustCompile(`^\r?\n`), token.NEW_LINE},
+	{regexp.MustCompile(`^[ \t\f\r]+`), token.IGNORE},
 	{regexp.MustCompile(`^#.*`), token.IGNORE},
-	{regexp.MustCompile(`^\(`), token.LPAREN},
-	{regexp.MustCompile(`^\)`), token.RPAREN},
-	{regexp.MustCompile(`^\[`), token.LBRACKET},
-	{regexp.MustCompile(`^\]`), token.RBRACKET},
-	{regexp.MustCompile(`^\{`), token.LBRACE},
-	{regexp.MustCompile(`^\}`), token.RBRACE},
-	{regexp.MustCompile(`^,`), token.COMMA},
-	{regexp.MustCompile(`^:`), token.COLON},
-	{regexp.MustCompile(`^;`), token.SEMICOLON},
-	{regexp.MustCompile(`^\.`), token.DOT},
 	{regexp.MustCompile(`^\S+`), token.UNKNOWN},
 }
 
-func New(input string) *Lexer {
-	return &Lexer{
-		input:       input,
-		pos:         0,
-		indentStack: []int{0},
-		startOfLine: true,
+// The operator patterns come from the token table, tried longest first
+// before the catch-all for unknown tokens.
+func init() {
+	unknown := tokenPatterns[len(tokenPatterns)-1]
+	tokenPatterns = tokenPatterns[:len(tokenPatterns)-1]
+	for _, op := range token.Operators() {
+		tokenPatterns = append(tokenPatterns, tokenPattern{regexp.MustCompile(`^` + regexp.QuoteMeta(op.Literal)), op.Type})
+	}
+	tokenPatterns = append(tokenPatterns, unknown)
+}
+
+// Identifiers follow PEP 3131: a letter or underscore, then letters, digits,
+// marks and connector punctuation.
+const (
+	identifierStart    = `_\p{L}\p{Nl}\x{2118}\x{212E}\x{309B}\x{309C}`
+	identifierContinue = identifierStart + `\p{Mn}\p{Mc}\p{Nd}\p{Pc}\x{00B7}\x{0387}\x{1369}-\x{1371}\x{19DA}`
+)
+
+var (
+	identifierPattern     = regexp.MustCompile(`^[` + identifierStart + `][` + identifierContinue + `]*`)
+	identifierRunePattern = regexp.MustCompile(`^[` + identifierContinue + `]$`)
+)
+
+func isIdentifierRune(r rune) bool {
+	return identifierRunePattern.MatchString(string(r))
+}
+
+func New(input string, opts ...Option) *Lexer {
+	l := &Lexer{
+		input:          input,
+		pos:            0,
+		indentStack:    []int{0},
+		altIndentStack: []int{0},
+		startOfLine:    true,
+		tabWidth:       8,
+		strictTabs:     true,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 func (l *Lexer) Tokenize() []token.Token {
 	l.tokens = []token.Token{}
 
+	l.decodeSource()
+	if len(l.errors) > 0 {
+		l.emit(token.EOF, "", "", l.pos)
+		return l.tokens
+	}
+
 	for l.pos < len(l.input) {
 		l.tokenizeNext()
 	}
 
-	if l.tokens[len(l.tokens)-1].Type != token.NEW_LINE {
-		l.tokens = append(l.tokens, token.Token{Type: token.NEW_LINE, Literal: "", Pos: l.pos})
+	for _, open := range l.brackets {
+		l.errorf("SyntaxError", open.Pos, "'%s' was never closed", open.Literal)
+		l.errors[len(l.errors)-1].Incomplete = true
+	}
+	if l.continued {
+		l.errorf("SyntaxError", l.pos, "unexpected EOF while parsing")
+		l.errors[len(l.errors)-1].Incomplete = true
+	}
+
+	if len(l.tokens) > 0 && l.tokens[len(l.tokens)-1].Type != token.NEW_LINE {
+		l.emit(token.NEW_LINE, "", "", l.pos)
 	}
 
 	// Ensure all dedents are closed at EOF
@@ -88,11 +163,14 @@ func (l *Lexer) Tokenize() []token.Token {
 	// }
 
 	for len(l.indentStack) > 1 {
-		l.tokens = append(l.tokens, token.Token{Type: token.DEDENT, Literal: "", Pos: l.pos})
-		l.indentStack = l.indentStack[:len(l.indentStack)-1]
+		l.emit(token.DEDENT, "", "", l.pos)
+		l.popIndent()
 	}
 
-	l.tokens = append(l.tokens, token.Token{Type: token.EOF, Literal: "", Pos: l.pos})
+	l.emit(token.EOF, "", "", l.pos)
+	if l.trivia && l.encoding != nil {
+		l.encodeTrivia()
+	}
 	return l.tokens
 }
 
@@ -103,30 +181,17 @@ func (l *Lexer) tokenizeNext() {
 	if l.startOfLine {
 		// Check if line is empty or only contains comment
 		if match := regexp.MustCompile(`^\s*(#.*)?(\n|$)`).FindString(input); match != "" {
-			l.pos += len(match)
+			l.skip(match)
 			return
 		}
 
 		// Capture indentation
-		indentation := regexp.MustCompile(`^[ \t]*`).FindString(input)
-		indentLevel := len(indentation)
-
-		// Check indentation changes
-		lastIndent := l.indentStack[len(l.indentStack)-1]
-		if indentLevel > lastIndent {
-			l.indentStack = append(l.indentStack, indentLevel)
-			l.tokens = append(l.tokens, token.Token{Type: token.INDENT, Literal: indentation, Pos: l.pos})
-		} else if indentLevel < lastIndent {
-			for len(l.indentStack) > 1 && indentLevel < l.indentStack[len(l.indentStack)-1] {
-				l.indentStack = l.indentStack[:len(l.indentStack)-1]
-				l.tokens = append(l.tokens, token.Token{Type: token.DEDENT, Literal: "", Pos: l.pos})
-			}
-			if l.indentStack[len(l.indentStack)-1] != indentLevel {
-				l.errors = append(l.errors, "unindent does not match any outer indentation level")
-			}
+		indentation := regexp.MustCompile(`^[ \t\f]*`).FindString(input)
+		if !l.indent(indentation) {
+			l.skip(indentation)
+		} else {
+			l.pos += len(indentation)
 		}
-
-		l.pos += indentLevel
 		l.startOfLine = false
 		return
 	}
@@ -136,20 +201,34 @@ func (l *Lexer) tokenizeNext() {
 		if match := pattern.regex.FindString(input); match != "" {
 			tokenLength := len(match)
 
-			// Skip ignored tokens
-			if pattern.tType == token.IGNORE {
-				l.pos += tokenLength
+			// \b only knows ASCII word characters, so a number running
+			// into a non-ASCII letter is part of a longer word.
+			if pattern.tType == token.NUMBER && splitsWord(match, input[tokenLength:]) {
+				continue
+			}
+
+			l.continued = pattern.tType == token.IGNORE && strings.HasPrefix(match, "\\")
+
+			// Skip ignored tokens, and newlines inside brackets
+			if pattern.tType == token.IGNORE || pattern.tType == token.NEW_LINE && len(l.brackets) > 0 {
+				l.skip(match)
 				return
 			}
 
-			l.tokens = append(l.tokens, token.Token{
-				Type:    pattern.tType,
-				Literal: match,
-				Pos:     l.pos,
-			})
+			tType, literal := pattern.tType, match
+			if tType == token.IDENTIFIER {
+				literal = norm.NFKC.String(match)
+				tType = token.LookupIdent(literal)
+			}
+			l.emit(tType, literal, match, l.pos)
 
-			if pattern.tType == token.NEW_LINE {
+			switch tType {
+			case token.NEW_LINE:
 				l.startOfLine = true
+			case token.LPAREN, token.LBRACKET, token.LBRACE:
+				l.brackets = append(l.brackets, l.tokens[len(l.tokens)-1])
+			case token.RPAREN, token.RBRACKET, token.RBRACE:
+				l.closeBracket(match)
 			}
 
 			l.pos += tokenLength
@@ -158,14 +237,156 @@ func (l *Lexer) tokenizeNext() {
 	}
 
 	// Unknown token handling
-	l.tokens = append(l.tokens, token.Token{
-		Type:    token.UNKNOWN,
-		Literal: string(input[0]),
-		Pos:     l.pos,
-	})
-	l.pos++
+	_, size := utf8.DecodeRuneInString(input)
+	l.emit(token.UNKNOWN, input[:size], input[:size], l.pos)
+	l.pos += size
+}
+
+// emit appends a token. raw is its source text, empty for the NEW_LINE,
+// DEDENT and EOF tokens added at the end of the input. Pending trivia
+// becomes leading trivia of the token, or trailing trivia of the token
+// before a newline.
+func (l *Lexer) emit(t token.TokenType, literal, raw string, pos int) {
+	tok := token.Token{Type: t, Literal: literal, Pos: pos}
+	if l.trivia {
+		tok.Raw = raw
+		if t == token.NEW_LINE && len(l.tokens) > 0 {
+			l.tokens[len(l.tokens)-1].Trailing += l.pending.String()
+		} else {
+			tok.Leading = l.pending.String()
+		}
+		l.pending.Reset()
+	}
+	l.tokens = append(l.tokens, tok)
+}
+
+// skip consumes text that produces no token, keeping it as trivia if asked to.
+func (l *Lexer) skip(text string) {
+	if l.trivia {
+		l.pending.WriteString(text)
+	}
+	l.pos += len(text)
+}
+
+// Untokenize joins tokens lexed with WithTrivia back into source text.
+func Untokenize(tokens []token.Token) string {
+	var out strings.Builder
+	for _, tok := range tokens {
+		out.WriteString(tok.Leading + tok.Raw + tok.Trailing)
+	}
+	return out.String()
+}
+
+// splitsWord reports whether a match ends in the middle of an identifier.
+func splitsWord(match, rest string) bool {
+	last, _ := utf8.DecodeLastRuneInString(match)
+	next, _ := utf8.DecodeRuneInString(rest)
+	return rest != "" && isIdentifierRune(last) && isIdentifierRune(next)
+}
+
+// indent emits the INDENT or DEDENT tokens for the indentation starting a
+// line, and reports whether an INDENT token holds the indentation. As in
+// Python, every line is measured twice, with tabs advancing to the tab width
+// and to 1, and in strict mode it is a TabError for the two measures to
+// disagree about the block structure.
+func (l *Lexer) indent(indentation string) bool {
+	col, altCol := l.measureIndent(indentation)
+	last := len(l.indentStack) - 1
+	inconsistent, indented := false, false
+
+	switch {
+	case col == l.indentStack[last]:
+		inconsistent = altCol != l.altIndentStack[last]
+	case col > l.indentStack[last]:
+		inconsistent = altCol <= l.altIndentStack[last]
+		l.indentStack = append(l.indentStack, col)
+		l.altIndentStack = append(l.altIndentStack, altCol)
+		l.emit(token.INDENT, indentation, indentation, l.pos)
+		indented = true
+	default:
+		for len(l.indentStack) > 1 && col < l.indentStack[len(l.indentStack)-1] {
+			l.popIndent()
+			l.emit(token.DEDENT, "", "", l.pos)
+		}
+		last = len(l.indentStack) - 1
+		if l.indentStack[last] != col {
+			l.errorf("IndentationError", l.pos, "unindent does not match any outer indentation level")
+			return false
+		}
+		inconsistent = altCol != l.altIndentStack[last]
+	}
+
+	if inconsistent && l.strictTabs {
+		l.errorf("TabError", l.pos, "inconsistent use of tabs and spaces in indentation")
+	}
+	return indented
+}
+
+// measureIndent returns the column indentation ends at with tabs advancing
+// to the tab width, and with tabs counting as one column. A form feed
+// resets both, as in Python.
+func (l *Lexer) measureIndent(indentation string) (col, altCol int) {
+	for _, c := range indentation {
+		switch c {
+		case ' ':
+			col++
+			altCol++
+		case '\t':
+			col = (col/l.tabWidth + 1) * l.tabWidth
+			altCol++
+		case '\f':
+			col, altCol = 0, 0
+		}
+	}
+	return col, altCol
+}
+
+func (l *Lexer) popIndent() {
+	l.indentStack = l.indentStack[:len(l.indentStack)-1]
+	l.altIndentStack = l.altIndentStack[:len(l.altIndentStack)-1]
+}
+
+var closingBrackets = map[string]string{")": "(", "]": "[", "}": "{"}
+
+// closeBracket pops the bracket closed by the token just emitted, reporting
+// a closing bracket without a matching opening one.
+func (l *Lexer) closeBracket(closing string) {
+	pos := l.tokens[len(l.tokens)-1].Pos
+	if len(l.brackets) == 0 {
+		l.errorf("SyntaxError", pos, "unmatched '%s'", closing)
+		return
+	}
+
+	open := l.brackets[len(l.brackets)-1]
+	l.brackets = l.brackets[:len(l.brackets)-1]
+	if closingBrackets[closing] != open.Literal {
+		line, _ := l.Position(open.Pos)
+		l.errorf("SyntaxError", pos, "closing parenthesis '%s' does not match opening parenthesis '%s' on line %d", closing, open.Literal, line)
+	}
+}
+
+// Position returns the 1-based line and column of a byte offset in the
+// input, such as the Pos of a token. Columns count characters, not bytes.
+func (l *Lexer) Position(offset int) (line, column int) {
+	before := l.input[:offset]
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return strings.Count(before, "\n") + 1, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+func (l *Lexer) errorf(kind string, pos int, format string, a ...any) {
+	line, column := l.Position(pos)
+	l.errors = append(l.errors, &Error{Kind: kind, Offset: pos, Line: line, Column: column, Msg: fmt.Sprintf(format, a...)})
 }
 
 func (p *Lexer) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
+
+// ErrorList returns the errors found by Tokenize with their kind and position.
+func (p *Lexer) ErrorList() []*Error {
 	return p.errors
 }
@@ -0,0 +1,231 @@
+package lexer
+
+import (
+	"fmt"
+	"slices"
+	"snek/token"
+	"testing"
+)
+
+// typesOf returns the types of tokens, for comparing token streams.
+func typesOf(tokens []token.Token) []token.TokenType {
+	types := make([]token.TokenType, len(tokens))
+	for i, tok := range tokens {
+		types[i] = tok.Type
+	}
+	return types
+}
+
+type tokenTest struct {
+	input    string
+	expected []token.TokenType
+}
+
+func testTokens(t *testing.T, tests []tokenTest, opts ...Option) {
+	t.Helper()
+	for _, tt := range tests {
+		l := New(tt.input, opts...)
+		tokens := l.Tokenize()
+		if errs := l.ErrorList(); len(errs) > 0 {
+			t.Errorf("lexing %q failed: %v", tt.input, errs[0])
+			continue
+		}
+		if types := typesOf(tokens); !slices.Equal(types, tt.expected) {
+			t.Errorf("lexing %q gave %v, want %v", tt.input, types, tt.expected)
+		}
+	}
+}
+
+type errorTest struct {
+	input      string
+	expected   string // The first error as "Kind: message"
+	incomplete bool
+}
+
+func testErrors(t *testing.T, tests []errorTest, opts ...Option) {
+	t.Helper()
+	for _, tt := range tests {
+		l := New(tt.input, opts...)
+		l.Tokenize()
+		errs := l.ErrorList()
+		if len(errs) == 0 {
+			t.Errorf("lexing %q succeeded, want %q", tt.input, tt.expected)
+			continue
+		}
+		if got := fmt.Sprintf("%s: %s", errs[0].Kind, errs[0]); got != tt.expected {
+			t.Errorf("lexing %q gave %q, want %q", tt.input, got, tt.expected)
+		}
+		if errs[0].Incomplete != tt.incomplete {
+			t.Errorf("lexing %q gave Incomplete = %v, want %v", tt.input, errs[0].Incomplete, tt.incomplete)
+		}
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	testTokens(t, []tokenTest{
+		{"x = (1,\n  2)\n", []token.TokenType{token.IDENTIFIER, token.ASSIGN, token.LPAREN, token.NUMBER, token.COMMA, token.NUMBER, token.RPAREN, token.NEW_LINE, token.EOF}},
+		{"a = {\n\n}\nb\n", []token.TokenType{token.IDENTIFIER, token.ASSIGN, token.LBRACE, token.RBRACE, token.NEW_LINE, token.IDENTIFIER, token.NEW_LINE, token.EOF}},
+		{"if x:\n    f([\n1])\n    y\n", []token.TokenType{token.IF, token.IDENTIFIER, token.COLON, token.NEW_LINE, token.INDENT, token.IDENTIFIER, token.LPAREN, token.LBRACKET, token.NUMBER, token.RBRACKET, token.RPAREN, token.NEW_LINE, token.IDENTIFIER, token.NEW_LINE, token.DEDENT, token.EOF}},
+		{"x = 1 + \\\n    2\n", []token.TokenType{token.IDENTIFIER, token.ASSIGN, token.NUMBER, token.PLUS, token.NUMBER, token.NEW_LINE, token.EOF}},
+	})
+	testErrors(t, []errorTest{
+		{"x = ]\n", "SyntaxError: line 1, column 5: unmatched ']'", false},
+		{"(1))\n", "SyntaxError: line 1, column 4: unmatched ')'", false},
+		{"(]\n", "SyntaxError: line 1, column 2: closing parenthesis ']' does not match opening parenthesis '(' on line 1", false},
+		{"{\n1)\n", "SyntaxError: line 2, column 2: closing parenthesis ')' does not match opening parenthesis '{' on line 1", false},
+		{"f(a\n", "SyntaxError: line 1, column 2: '(' was never closed", true},
+		{"x = [1,\n(2\n", "SyntaxError: line 1, column 5: '[' was never closed", true},
+		{"x = 1 + \\\n", "SyntaxError: line 2, column 1: unexpected EOF while parsing", true},
+	})
+}
+
+func TestIndentation(t *testing.T) {
+	block := []token.TokenType{token.IF, token.IDENTIFIER, token.COLON, token.NEW_LINE, token.INDENT, token.IDENTIFIER, token.NEW_LINE, token.IDENTIFIER, token.NEW_LINE, token.DEDENT, token.EOF}
+	testTokens(t, []tokenTest{
+		{"if x:\n    a\n    b\n", block},
+		{"if x:\n\ta\n\tb\n", block},
+		{"if x:\n    a\n\f    b\n", block},
+	})
+	testErrors(t, []errorTest{
+		{"if x:\n\tif y:\n        pass\n", "TabError: line 3, column 1: inconsistent use of tabs and spaces in indentation", false},
+		{"if x:\n        a\n\tb\n", "TabError: line 3, column 1: inconsistent use of tabs and spaces in indentation", false},
+		{"if x:\n\ta\n    b\n", "IndentationError: line 3, column 1: unindent does not match any outer indentation level", false},
+		{"if x:\n  \ta\n\tb\n", "TabError: line 3, column 1: inconsistent use of tabs and spaces in indentation", false},
+	})
+
+	// With a tab width of 4, a tab and four spaces reach the same column,
+	// which is only ambiguous in strict mode.
+	testErrors(t, []errorTest{
+		{"if x:\n\ta\n    b\n", "TabError: line 3, column 1: inconsistent use of tabs and spaces in indentation", false},
+	}, WithTabWidth(4))
+	testTokens(t, []tokenTest{
+		{"if x:\n\ta\n    b\n", block},
+		{"if x:\n\ta\n  \tb\n", block},
+	}, WithTabWidth(4), WithStrictTabs(false))
+	testTokens(t, []tokenTest{
+		{"if x:\n\ta\n        b\n", block},
+	}, WithStrictTabs(false))
+}
+
+// literalsOf returns the literals of the tokens lexed from input, up to the
+// first NEW_LINE.
+func literalsOf(t *testing.T, input string) []string {
+	t.Helper()
+	l := New(input)
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		t.Fatalf("lexing %q failed: %v", input, errs[0])
+	}
+	literals := []string{}
+	for _, tok := range tokens {
+		if tok.Type == token.NEW_LINE {
+			break
+		}
+		literals = append(literals, tok.Literal)
+	}
+	return literals
+}
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"ﬁle = ｘ\n", []string{"file", "=", "x"}},
+		{"é = 1\n", []string{"é", "=", "1"}},
+		{"2é\n", []string{"2é"}},
+	}
+	for _, tt := range tests {
+		if got := literalsOf(t, tt.input); !slices.Equal(got, tt.expected) {
+			t.Errorf("lexing %q gave %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSourceEncoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"\uFEFFx = 1\n", []string{"x", "=", "1"}},
+		{"# -*- coding: latin-1 -*-\nx = '\xe9'\n", []string{"x", "=", "'é'"}},
+		{"#!/usr/bin/env python\n# vim: set fileencoding=iso-8859-15 :\nx = '\xa4'\n", []string{"x", "=", "'€'"}},
+		{"# coding: utf_8\nx = 'é'\n", []string{"x", "=", "'é'"}},
+		{"\uFEFF# coding: utf-8\nx\n", []string{"x"}},
+	}
+	for _, tt := range tests {
+		if got := literalsOf(t, tt.input); !slices.Equal(got, tt.expected) {
+			t.Errorf("lexing %q gave %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	testErrors(t, []errorTest{
+		{"\uFEFF# coding: latin-1\nx\n", "SyntaxError: line 1, column 1: encoding problem: iso-8859-1 with BOM", false},
+		{"# coding: bogus\nx\n", "SyntaxError: line 1, column 1: unknown encoding: bogus", false},
+		{"x = '\xff'\n", "SyntaxError: line 1, column 6: (unicode error) 'utf-8' codec can't decode byte 0xff", false},
+		{"x = 1\n# coding: latin-1\ny = '\xe9'\n", "SyntaxError: line 3, column 6: (unicode error) 'utf-8' codec can't decode byte 0xe9", false},
+	})
+}
+
+func TestPositions(t *testing.T) {
+	testErrors(t, []errorTest{
+		{"é = (\n", "SyntaxError: line 1, column 5: '(' was never closed", true},
+		{"x = 1\nñá = ]\n", "SyntaxError: line 2, column 6: unmatched ']'", false},
+	})
+
+	l := New("ab\nçé = 1\n")
+	tokens := l.Tokenize()
+	for _, tt := range []struct {
+		index        int
+		line, column int
+	}{
+		{0, 1, 1},
+		{2, 2, 1},
+		{3, 2, 4},
+		{4, 2, 6},
+	} {
+		if line, column := l.Position(tokens[tt.index].Pos); line != tt.line || column != tt.column {
+			t.Errorf("token %d %q is at %d:%d, want %d:%d", tt.index, tokens[tt.index].Literal, line, column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestUntokenize(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"\n\n",
+		"a",
+		"x = 1  # one\n",
+		"if x:\n    # comment\n\n    y = (1,\n  2) \\\n        + 3\nz\n",
+		"def f():\n\tpass\n\n\n# end",
+		"ﬁ = 1\r\n",
+		"\uFEFFx\n",
+		"  \n\fx = 1;y\n",
+		"x = [\n",
+		"x = ]\n",
+		"if x:\n\ta\n    b\n",
+		"# -*- coding: latin-1 -*-\nx = '\xe9'  # \xe0\n",
+		"#!/usr/bin/env python\n# vim: set fileencoding=iso-8859-15 :\nx = '\xa4'\n",
+	} {
+		l := New(input, WithTrivia(true))
+		tokens := l.Tokenize()
+		if got := Untokenize(tokens); got != input {
+			t.Errorf("Untokenize(Tokenize(%q)) = %q", input, got)
+		}
+	}
+
+	tokens := New("ﬁ = x  # c\n", WithTrivia(true)).Tokenize()
+	if tok := tokens[0]; tok.Literal != "fi" || tok.Raw != "ﬁ" {
+		t.Errorf("got literal %q and raw %q, want \"fi\" and \"ﬁ\"", tok.Literal, tok.Raw)
+	}
+	if tok := tokens[2]; tok.Leading != " " || tok.Trailing != "  # c" {
+		t.Errorf("got leading %q and trailing %q, want \" \" and \"  # c\"", tok.Leading, tok.Trailing)
+	}
+	tokens = New("# coding: latin-1\nx = '\xe9'\n", WithTrivia(true)).Tokenize()
+	if tok := tokens[2]; tok.Literal != "'é'" || tok.Raw != "'\xe9'" {
+		t.Errorf("got literal %q and raw %q, want \"'é'\" and \"'\\xe9'\"", tok.Literal, tok.Raw)
+	}
+	if tok := New("x\n").Tokenize()[0]; tok.Raw != "" {
+		t.Errorf("got raw %q without trivia, want \"\"", tok.Raw)
+	}
+}
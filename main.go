@@ -1,56 +1,63 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 	"snek/evaluator"
 	"snek/lexer"
+	"snek/object"
 	"snek/parser"
-	"snek/token"
+	"snek/repl"
 )
 
 func main() {
-	code := `
-3`
+	optimize := flag.Bool("O", false, "skip assert statements")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: snek [-O] [file]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	l := lexer.New(code)
-	tokens := l.Tokenize()
-	PrintTokens(tokens)
+	var opts []evaluator.Option
+	if *optimize {
+		opts = append(opts, evaluator.WithOptimize())
+	}
 
-	if len(l.Errors()) > 0 {
-		io.WriteString(os.Stdout, "Lexer Errors:\n")
-		for _, msg := range l.Errors() {
-			io.WriteString(os.Stdout, "\t- "+msg+"\n")
+	switch flag.NArg() {
+	case 0:
+		repl.Start(os.Stdin, os.Stdout, opts...)
+	case 1:
+		if err := runFile(flag.Arg(0), opts...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-		return
+	default:
+		flag.Usage()
+		os.Exit(2)
 	}
+}
 
-	fmt.Println("----------")
-
-	p := parser.New(tokens)
-	ast, err := p.ParseFile()
-
-	fmt.Println("----------")
-
+// runFile runs the program in path as the __main__ module, importing modules
+// from its directory.
+func runFile(path string, opts ...evaluator.Option) error {
+	src, err := os.ReadFile(path)
 	if err != nil {
-		io.WriteString(os.Stdout, "Parse Error: "+err.Error()+"\n")
-		return
+		return err
 	}
 
-	evaluator.DebugPrint(ast, 0)
-
-	fmt.Println("----------")
-
-	fmt.Println(ast.String())
-}
-
-func PrintTokens(tokens []token.Token) {
-	fmt.Println("Pos  | Type                 | Literal")
-	fmt.Println("-----------------------------------------------------")
-
-	for _, tok := range tokens {
-		fmt.Printf("%-4d | %-20s | %q\n",
-			tok.Pos, tok.Type, tok.Literal)
+	l := lexer.New(string(src))
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		return fmt.Errorf("%s: %s (%s)", errs[0].Kind, errs[0], path)
+	}
+	prog, err := parser.New(tokens).ParseFile()
+	if err != nil {
+		return fmt.Errorf("SyntaxError: %s (%s)", err, path)
 	}
+
+	opts = append([]evaluator.Option{evaluator.WithLoaders(evaluator.NewDirLoader(filepath.Dir(path)))}, opts...)
+	_, err = evaluator.New(opts...).Eval(prog, object.NewModule("__main__", path, false).Env)
+	return err
 }
@@ -0,0 +1,10 @@
+package object
+
+// ContextManager lets objects implemented in Go, such as file handles and
+// locks exposed by a host, be used in with statements. Exit receives the
+// exception raised in the block, or nil, and reports whether to suppress it.
+type ContextManager interface {
+	Object
+	Enter() (Object, error)
+	Exit(exc *Exception) (bool, error)
+}
@@ -0,0 +1,234 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HashKey identifies a dict key. Numbers that compare equal share a key, as
+// they do in Python.
+type HashKey struct {
+	Type  ObjectType
+	Value string
+}
+
+// Hash returns the key of obj, or false if obj is unhashable. Objects without
+// value semantics hash by identity; for instances whose class defines
+// __hash__, the evaluator finds an equal key with SetHashed and Hashed.
+func Hash(obj Object) (HashKey, bool) {
+	switch obj := obj.(type) {
+	case *None:
+		return HashKey{Type: NONE_OBJ}, true
+	case *Boolean:
+		if obj.Value {
+			return HashKey{Type: INT_OBJ, Value: "1"}, true
+		}
+		return HashKey{Type: INT_OBJ, Value: "0"}, true
+	case *Integer:
+		return HashKey{Type: INT_OBJ, Value: strconv.FormatInt(obj.Value, 10)}, true
+	case *Float:
+		if obj.Value == math.Trunc(obj.Value) && math.Abs(obj.Value) < 1<<63 {
+			return HashKey{Type: INT_OBJ, Value: strconv.FormatInt(int64(obj.Value), 10)}, true
+		}
+		return HashKey{Type: FLOAT_OBJ, Value: strconv.FormatFloat(obj.Value, 'g', -1, 64)}, true
+	case *String:
+		return HashKey{Type: STR_OBJ, Value: obj.Value}, true
+	case *Tuple:
+		var out strings.Builder
+		for _, elem := range obj.Elements {
+			key, ok := Hash(elem)
+			if !ok {
+				return HashKey{}, false
+			}
+			fmt.Fprintf(&out, "%s:%d:%s;", key.Type, len(key.Value), key.Value)
+		}
+		return HashKey{Type: TUPLE_OBJ, Value: out.String()}, true
+	case *List, *Dict, *Set:
+		return HashKey{}, false
+	}
+
+	return HashKey{Type: obj.Type(), Value: fmt.Sprintf("%p", obj)}, true
+}
+
+type DictPair struct {
+	Key   Object
+	Value Object
+
+	hash   int64 // The result of the key's __hash__, if hashed is set
+	hashed bool
+}
+
+// Dict is an insertion ordered hash map.
+type Dict struct {
+	pairs  map[HashKey]*DictPair
+	keys   []HashKey
+	hashed map[int64][]Object // Keys stored with SetHashed, by hash
+}
+
+func NewDict() *Dict {
+	return &Dict{pairs: map[HashKey]*DictPair{}}
+}
+
+func (d *Dict) Type() ObjectType { return DICT_OBJ }
+func (d *Dict) Inspect() string {
+	parts := make([]string, len(d.keys))
+	for i, key := range d.keys {
+		pair := d.pairs[key]
+		parts[i] = pair.Key.Inspect() + ": " + pair.Value.Inspect()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (d *Dict) Len() int { return len(d.keys) }
+
+func (d *Dict) Get(key HashKey) (Object, bool) {
+	if pair, ok := d.pairs[key]; ok {
+		return pair.Value, true
+	}
+	return nil, false
+}
+
+// Set stores value under key, keeping the original key object if one exists.
+func (d *Dict) Set(key HashKey, k, value Object) {
+	if pair, ok := d.pairs[key]; ok {
+		pair.Value = value
+		return
+	}
+	d.pairs[key] = &DictPair{Key: k, Value: value}
+	d.keys = append(d.keys, key)
+}
+
+// SetHashed is Set for a key whose class defines __hash__, recording hash so
+// that Hashed finds the key.
+func (d *Dict) SetHashed(key HashKey, hash int64, k, value Object) {
+	if _, ok := d.pairs[key]; !ok {
+		if d.hashed == nil {
+			d.hashed = map[int64][]Object{}
+		}
+		d.hashed[hash] = append(d.hashed[hash], k)
+	}
+	d.Set(key, k, value)
+	pair := d.pairs[key]
+	pair.hash, pair.hashed = hash, true
+}
+
+// Hashed returns the keys stored with SetHashed under hash.
+func (d *Dict) Hashed(hash int64) []Object {
+	return d.hashed[hash]
+}
+
+func (d *Dict) Delete(key HashKey) (*DictPair, bool) {
+	pair, ok := d.pairs[key]
+	if !ok {
+		return nil, false
+	}
+	delete(d.pairs, key)
+	i := slices.Index(d.keys, key)
+	d.keys = slices.Delete(d.keys, i, i+1)
+	if pair.hashed {
+		// Copied, since the evaluator may be iterating over the old slice.
+		d.hashed[pair.hash] = slices.DeleteFunc(slices.Clone(d.hashed[pair.hash]), func(k Object) bool { return k == pair.Key })
+	}
+	return pair, true
+}
+
+func (d *Dict) Clear() {
+	d.pairs = map[HashKey]*DictPair{}
+	d.keys = nil
+	d.hashed = nil
+}
+
+// Copy returns a shallow copy of d.
+func (d *Dict) Copy() *Dict {
+	c := NewDict()
+	for _, key := range d.keys {
+		pair := d.pairs[key]
+		if pair.hashed {
+			c.SetHashed(key, pair.hash, pair.Key, pair.Value)
+		} else {
+			c.Set(key, pair.Key, pair.Value)
+		}
+	}
+	return c
+}
+
+// PairAt returns the i-th pair in insertion order.
+func (d *Dict) PairAt(i int) *DictPair {
+	return d.pairs[d.keys[i]]
+}
+
+type DictViewKind int
+
+const (
+	DICT_KEYS DictViewKind = iota
+	DICT_VALUES
+	DICT_ITEMS
+)
+
+var dictViewNames = map[DictViewKind]string{
+	DICT_KEYS:   "dict_keys",
+	DICT_VALUES: "dict_values",
+	DICT_ITEMS:  "dict_items",
+}
+
+var dictIteratorNames = map[DictViewKind]string{
+	DICT_KEYS:   "dict_keyiterator",
+	DICT_VALUES: "dict_valueiterator",
+	DICT_ITEMS:  "dict_itemiterator",
+}
+
+// DictView is a live view of a dict's keys, values or items.
+type DictView struct {
+	Dict *Dict
+	Kind DictViewKind
+}
+
+func (v *DictView) Type() ObjectType { return ObjectType(dictViewNames[v.Kind]) }
+func (v *DictView) Inspect() string {
+	items := make([]Object, v.Dict.Len())
+	for i := range items {
+		items[i] = v.Item(i)
+	}
+	return dictViewNames[v.Kind] + "([" + inspectAll(items) + "])"
+}
+
+// Item returns the i-th element of the view.
+func (v *DictView) Item(i int) Object {
+	pair := v.Dict.PairAt(i)
+	switch v.Kind {
+	case DICT_KEYS:
+		return pair.Key
+	case DICT_VALUES:
+		return pair.Value
+	default:
+		return &Tuple{Elements: []Object{pair.Key, pair.Value}}
+	}
+}
+
+type DictIterator struct {
+	View  *DictView
+	index int
+	size  int
+}
+
+func NewDictIterator(view *DictView) *DictIterator {
+	return &DictIterator{View: view, size: view.Dict.Len()}
+}
+
+func (it *DictIterator) Type() ObjectType { return ObjectType(dictIteratorNames[it.View.Kind]) }
+func (it *DictIterator) Inspect() string  { return "<" + string(it.Type()) + " object>" }
+
+func (it *DictIterator) Next() (Object, error) {
+	if it.View.Dict.Len() != it.size {
+		it.size = -1
+		return nil, NewError(RuntimeError, "dictionary changed size during iteration")
+	}
+	if it.index >= it.size {
+		return nil, nil
+	}
+	it.index++
+	return it.View.Item(it.index - 1), nil
+}
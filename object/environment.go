@@ -0,0 +1,116 @@
+package object
+
+import (
+	"maps"
+	"slices"
+)
+
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+	yield YieldFunc // Set in the frame of a running generator
+
+	comprehension bool
+	class         bool
+	locals        map[string]bool // Names bound anywhere in a function's body
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object)}
+}
+
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// NewFunctionEnvironment creates the frame of a function call. Looking up one
+// of locals stops there, even before the body has bound it.
+func NewFunctionEnvironment(outer *Environment, locals map[string]bool) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.locals = locals
+	return env
+}
+
+// NewComprehensionEnvironment creates the scope holding a comprehension's
+// loop variables.
+func NewComprehensionEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.comprehension = true
+	return env
+}
+
+// NamedScope returns the environment an assignment expression binds in: the
+// nearest one that does not belong to a comprehension.
+func (e *Environment) NamedScope() *Environment {
+	for e.comprehension && e.outer != nil {
+		e = e.outer
+	}
+	return e
+}
+
+// NewClassEnvironment creates the scope a class body runs in.
+func NewClassEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.class = true
+	return env
+}
+
+// FunctionScope returns the environment functions defined in e close over.
+// Class bodies are skipped, so methods do not see class attributes as names.
+func (e *Environment) FunctionScope() *Environment {
+	for e.class && e.outer != nil {
+		e = e.outer
+	}
+	return e
+}
+
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil && !e.locals[name] {
+		return e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// Unbound reports whether a lookup of name from e stops at a function frame
+// where it is local but has no value yet.
+func (e *Environment) Unbound(name string) bool {
+	for ; e != nil; e = e.outer {
+		if _, ok := e.store[name]; ok {
+			return false
+		}
+		if e.locals[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names defined directly in e, sorted.
+func (e *Environment) Names() []string {
+	return slices.Sorted(maps.Keys(e.store))
+}
+
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Delete removes a name defined directly in e, reporting whether it was there.
+func (e *Environment) Delete(name string) bool {
+	_, ok := e.store[name]
+	delete(e.store, name)
+	return ok
+}
+
+// Yield returns the function that suspends the generator running in this
+// frame, or nil outside a generator.
+func (e *Environment) Yield() YieldFunc {
+	return e.yield
+}
+
+func (e *Environment) SetYield(fn YieldFunc) {
+	e.yield = fn
+}
@@ -0,0 +1,121 @@
+package object
+
+import "fmt"
+
+type Class struct {
+	Name  string
+	Bases []*Class
+	Attrs map[string]Object
+
+	// Constructor creates instances of built-in types when the class is called.
+	Constructor BuiltinFunction
+}
+
+func NewClass(name string, bases ...*Class) *Class {
+	return &Class{Name: name, Bases: bases, Attrs: map[string]Object{}}
+}
+
+func (c *Class) Type() ObjectType { return CLASS_OBJ }
+func (c *Class) Inspect() string  { return "<class '" + c.QualifiedName() + "'>" }
+
+// QualifiedName prefixes the name of a user-defined class with its module.
+func (c *Class) QualifiedName() string {
+	if mod, ok := c.Attrs["__module__"].(*String); ok {
+		return mod.Value + "." + c.Name
+	}
+	return c.Name
+}
+
+func (c *Class) IsSubclass(other *Class) bool {
+	if c == other {
+		return true
+	}
+	for _, base := range c.Bases {
+		if base.IsSubclass(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupAttr finds an attribute on the class or, depth first, on its bases.
+func (c *Class) LookupAttr(name string) (Object, bool) {
+	if val, ok := c.Attrs[name]; ok {
+		return val, true
+	}
+	for _, base := range c.Bases {
+		if val, ok := base.LookupAttr(name); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+var ObjectClass = NewClass("object")
+
+var (
+	BaseExceptionClass  = NewClass("BaseException", ObjectClass)
+	GeneratorExit       = NewClass("GeneratorExit", BaseExceptionClass)
+	ExceptionClass      = NewClass("Exception", BaseExceptionClass)
+	ArithmeticError     = NewClass("ArithmeticError", ExceptionClass)
+	ZeroDivisionError   = NewClass("ZeroDivisionError", ArithmeticError)
+	OverflowError       = NewClass("OverflowError", ArithmeticError)
+	LookupError         = NewClass("LookupError", ExceptionClass)
+	IndexError          = NewClass("IndexError", LookupError)
+	KeyError            = NewClass("KeyError", LookupError)
+	NameError           = NewClass("NameError", ExceptionClass)
+	UnboundLocalError   = NewClass("UnboundLocalError", NameError)
+	ImportError         = NewClass("ImportError", ExceptionClass)
+	ModuleNotFoundError = NewClass("ModuleNotFoundError", ImportError)
+	SyntaxError         = NewClass("SyntaxError", ExceptionClass)
+	IndentationError    = NewClass("IndentationError", SyntaxError)
+	TabError            = NewClass("TabError", IndentationError)
+	AttributeError      = NewClass("AttributeError", ExceptionClass)
+	TypeError           = NewClass("TypeError", ExceptionClass)
+	ValueError          = NewClass("ValueError", ExceptionClass)
+	RuntimeError        = NewClass("RuntimeError", ExceptionClass)
+	NotImplementedError = NewClass("NotImplementedError", RuntimeError)
+	RecursionError      = NewClass("RecursionError", RuntimeError)
+	StopIteration       = NewClass("StopIteration", ExceptionClass)
+	AssertionError      = NewClass("AssertionError", ExceptionClass)
+)
+
+type Exception struct {
+	Class *Class
+	Args  []Object
+	Attrs map[string]Object // Set by user-defined exception classes, nil until then
+
+	Cause           *Exception // Set by 'raise ... from cause'
+	Context         *Exception // The exception being handled when this one was raised
+	SuppressContext bool       // Set by 'raise ... from', hides Context when printed
+}
+
+func NewError(class *Class, format string, a ...any) *Exception {
+	return &Exception{Class: class, Args: []Object{&String{Value: fmt.Sprintf(format, a...)}}}
+}
+
+func (e *Exception) Type() ObjectType { return ObjectType(e.Class.Name) }
+func (e *Exception) Inspect() string  { return e.Class.Name + "(" + inspectAll(e.Args) + ")" }
+
+// Message is the str() of the exception: its single argument, or the tuple of all of them.
+func (e *Exception) Message() string {
+	switch len(e.Args) {
+	case 0:
+		return ""
+	case 1:
+		if s, ok := e.Args[0].(*String); ok && !e.Class.IsSubclass(KeyError) {
+			return s.Value
+		}
+		return e.Args[0].Inspect()
+	default:
+		return (&Tuple{Elements: e.Args}).Inspect()
+	}
+}
+
+func (e *Exception) Error() string {
+	msg := e.Message()
+	if msg == "" {
+		return e.Class.Name
+	}
+	return e.Class.Name + ": " + msg
+}
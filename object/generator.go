@@ -0,0 +1,146 @@
+package object
+
+import "runtime"
+
+// YieldFunc suspends a generator body, handing value to whoever resumed it.
+// It returns the value sent back in, or the exception thrown in.
+type YieldFunc func(value Object) (Object, error)
+
+// GeneratorBody runs a generator function to completion, suspending at each
+// call to yield, and returns the function's return value.
+type GeneratorBody func(yield YieldFunc) (Object, error)
+
+type resumeMsg struct {
+	value Object
+	err   error
+}
+
+type yieldMsg struct {
+	value Object
+	err   error
+	done  bool
+}
+
+// coroutine runs a generator body on its own goroutine. Control passes back and
+// forth over unbuffered channels, so only one side ever runs at a time.
+type coroutine struct {
+	body     GeneratorBody
+	resume   chan resumeMsg
+	yield    chan yieldMsg
+	started  bool
+	running  bool
+	finished bool
+}
+
+// Generator is the object returned by calling a generator function. The
+// coroutine is kept separate so an abandoned generator can be collected and
+// its goroutine released.
+type Generator struct {
+	Name string
+	co   *coroutine
+}
+
+func NewGenerator(name string, body GeneratorBody) *Generator {
+	g := &Generator{Name: name, co: &coroutine{
+		body:   body,
+		resume: make(chan resumeMsg),
+		yield:  make(chan yieldMsg),
+	}}
+	runtime.SetFinalizer(g, func(g *Generator) { g.co.release() })
+	return g
+}
+
+func (g *Generator) Type() ObjectType { return GENERATOR_OBJ }
+func (g *Generator) Inspect() string  { return "<generator object " + g.Name + ">" }
+
+func (g *Generator) Finished() bool { return g.co.finished }
+
+// Resume runs the generator until its next yield. A non-nil err is raised at
+// the suspended yield instead of sending value. When the body returns, Resume
+// raises StopIteration carrying the return value.
+func (g *Generator) Resume(value Object, err error) (Object, error) {
+	co := g.co
+	switch {
+	case co.running:
+		return nil, NewError(ValueError, "generator already executing")
+	case co.finished:
+		if err != nil {
+			return nil, err
+		}
+		return nil, &Exception{Class: StopIteration}
+	case !co.started:
+		if err != nil {
+			co.finished = true
+			return nil, err
+		}
+		if value != NONE {
+			return nil, NewError(TypeError, "can't send non-None value to a just-started generator")
+		}
+		co.started = true
+		co.running = true
+		go co.run()
+	default:
+		co.running = true
+		co.resume <- resumeMsg{value: value, err: err}
+	}
+
+	msg := <-co.yield
+	co.running = false
+	co.finished = msg.done
+	return msg.value, msg.err
+}
+
+func (g *Generator) Next() (Object, error) {
+	item, err := g.Resume(NONE, nil)
+	if exc, ok := err.(*Exception); ok && exc.Class == StopIteration && g.co.finished {
+		return nil, nil
+	}
+	return item, err
+}
+
+// Close raises GeneratorExit inside the generator so its cleanup code runs.
+func (g *Generator) Close() error {
+	if !g.co.started || g.co.finished {
+		g.co.finished = true
+		return nil
+	}
+
+	_, err := g.Resume(nil, &Exception{Class: GeneratorExit})
+	if err == nil {
+		return NewError(RuntimeError, "generator ignored GeneratorExit")
+	}
+	if exc, ok := err.(*Exception); ok && (exc.Class.IsSubclass(GeneratorExit) || exc.Class.IsSubclass(StopIteration)) {
+		return nil
+	}
+	return err
+}
+
+func (co *coroutine) run() {
+	ret, err := co.body(co.suspend)
+	if exc, ok := err.(*Exception); ok && exc.Class.IsSubclass(StopIteration) {
+		err = NewError(RuntimeError, "generator raised StopIteration")
+	} else if err == nil {
+		stop := &Exception{Class: StopIteration}
+		if ret != NONE {
+			stop.Args = []Object{ret}
+		}
+		err = stop
+	}
+	co.yield <- yieldMsg{err: err, done: true}
+}
+
+func (co *coroutine) suspend(value Object) (Object, error) {
+	co.yield <- yieldMsg{value: value}
+	msg, ok := <-co.resume
+	if !ok {
+		runtime.Goexit()
+	}
+	return msg.value, msg.err
+}
+
+// release ends the goroutine of a generator that was never run to completion.
+func (co *coroutine) release() {
+	if co.started && !co.finished {
+		close(co.resume)
+	}
+}
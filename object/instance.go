@@ -0,0 +1,46 @@
+package object
+
+import "fmt"
+
+// Instance is an object of a user-defined class.
+type Instance struct {
+	Class *Class
+	Attrs map[string]Object
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{Class: class, Attrs: map[string]Object{}}
+}
+
+func (i *Instance) Type() ObjectType { return ObjectType(i.Class.Name) }
+func (i *Instance) Inspect() string {
+	return fmt.Sprintf("<%s object at %p>", i.Class.QualifiedName(), i)
+}
+
+// StaticMethod wraps a function so it is not bound when looked up.
+type StaticMethod struct {
+	Function Object
+}
+
+func (m *StaticMethod) Type() ObjectType { return STATICMETHOD_OBJ }
+func (m *StaticMethod) Inspect() string  { return "<staticmethod(" + m.Function.Inspect() + ")>" }
+
+// ClassMethod wraps a function so it is bound to the class rather than the instance.
+type ClassMethod struct {
+	Function Object
+}
+
+func (m *ClassMethod) Type() ObjectType { return CLASSMETHOD_OBJ }
+func (m *ClassMethod) Inspect() string  { return "<classmethod(" + m.Function.Inspect() + ")>" }
+
+// Property is a managed attribute. Get, Set and Delete are nil when the
+// corresponding accessor is missing.
+type Property struct {
+	Get    Object
+	Set    Object
+	Delete Object
+	Doc    Object
+}
+
+func (p *Property) Type() ObjectType { return PROPERTY_OBJ }
+func (p *Property) Inspect() string  { return fmt.Sprintf("<property object at %p>", p) }
@@ -0,0 +1,60 @@
+package object
+
+// Iterator produces the items of an iterable one at a time. Next returns a nil
+// object once the iterator is exhausted.
+type Iterator interface {
+	Object
+	Next() (Object, error)
+}
+
+type ListIterator struct {
+	List  *List
+	index int
+}
+
+func (it *ListIterator) Type() ObjectType { return "list_iterator" }
+func (it *ListIterator) Inspect() string  { return "<list_iterator object>" }
+
+func (it *ListIterator) Next() (Object, error) {
+	if it.index >= len(it.List.Elements) {
+		return nil, nil
+	}
+	it.index++
+	return it.List.Elements[it.index-1], nil
+}
+
+type TupleIterator struct {
+	Tuple *Tuple
+	index int
+}
+
+func (it *TupleIterator) Type() ObjectType { return "tuple_iterator" }
+func (it *TupleIterator) Inspect() string  { return "<tuple_iterator object>" }
+
+func (it *TupleIterator) Next() (Object, error) {
+	if it.index >= len(it.Tuple.Elements) {
+		return nil, nil
+	}
+	it.index++
+	return it.Tuple.Elements[it.index-1], nil
+}
+
+type StringIterator struct {
+	runes []rune
+	index int
+}
+
+func NewStringIterator(s *String) *StringIterator {
+	return &StringIterator{runes: []rune(s.Value)}
+}
+
+func (it *StringIterator) Type() ObjectType { return "str_iterator" }
+func (it *StringIterator) Inspect() string  { return "<str_iterator object>" }
+
+func (it *StringIterator) Next() (Object, error) {
+	if it.index >= len(it.runes) {
+		return nil, nil
+	}
+	it.index++
+	return &String{Value: string(it.runes[it.index-1])}, nil
+}
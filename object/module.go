@@ -0,0 +1,36 @@
+package object
+
+import "strings"
+
+// Module is an imported source file or package. Its attributes are the
+// globals of Env.
+type Module struct {
+	Name      string
+	Path      string // The file the module was loaded from, empty for native modules
+	IsPackage bool
+	Env       *Environment
+}
+
+func NewModule(name, path string, isPackage bool) *Module {
+	m := &Module{Name: name, Path: path, IsPackage: isPackage, Env: NewEnvironment()}
+	m.Env.Set("__name__", &String{Value: name})
+
+	pkg := name
+	if !isPackage {
+		pkg = name[:max(strings.LastIndex(name, "."), 0)]
+	}
+	m.Env.Set("__package__", &String{Value: pkg})
+
+	if path != "" {
+		m.Env.Set("__file__", &String{Value: path})
+	}
+	return m
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string {
+	if m.Path == "" {
+		return "<module '" + m.Name + "' (built-in)>"
+	}
+	return "<module '" + m.Name + "' from '" + m.Path + "'>"
+}
@@ -0,0 +1,242 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"snek/ast"
+	"strconv"
+	"strings"
+)
+
+type ObjectType string
+
+const (
+	NONE_OBJ      = "NoneType"
+	BOOL_OBJ      = "bool"
+	INT_OBJ       = "int"
+	FLOAT_OBJ     = "float"
+	STR_OBJ       = "str"
+	TUPLE_OBJ     = "tuple"
+	LIST_OBJ      = "list"
+	DICT_OBJ      = "dict"
+	RANGE_OBJ     = "range"
+	CLASS_OBJ     = "type"
+	FUNCTION_OBJ  = "function"
+	BUILTIN_OBJ   = "builtin_function_or_method"
+	METHOD_OBJ    = "method"
+	GENERATOR_OBJ = "generator"
+	SET_OBJ       = "set"
+	MODULE_OBJ    = "module"
+	SLICE_OBJ     = "slice"
+
+	STATICMETHOD_OBJ = "staticmethod"
+	CLASSMETHOD_OBJ  = "classmethod"
+	PROPERTY_OBJ     = "property"
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+var (
+	NONE  = &None{}
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
+func NativeBool(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+type None struct{}
+
+func (n *None) Type() ObjectType { return NONE_OBJ }
+func (n *None) Inspect() string  { return "None" }
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOL_OBJ }
+func (b *Boolean) Inspect() string {
+	if b.Value {
+		return "True"
+	}
+	return "False"
+}
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INT_OBJ }
+func (i *Integer) Inspect() string  { return strconv.FormatInt(i.Value, 10) }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string  { return FormatFloat(f.Value) }
+
+// FormatFloat mirrors Python's float repr: the shortest round-tripping
+// digits, always with a fractional part or an exponent.
+func FormatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	exp := 0
+	if f != 0 {
+		exp = int(math.Floor(math.Log10(math.Abs(f))))
+	}
+	if exp < -4 || exp >= 16 {
+		return strconv.FormatFloat(f, 'e', -1, 64)
+	}
+
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STR_OBJ }
+func (s *String) Inspect() string  { return QuoteString(s.Value) }
+
+// QuoteString produces a Python style string literal, preferring single quotes.
+func QuoteString(s string) string {
+	quote := '\''
+	if strings.ContainsRune(s, '\'') && !strings.ContainsRune(s, '"') {
+		quote = '"'
+	}
+
+	var out strings.Builder
+	out.WriteRune(quote)
+	for _, r := range s {
+		switch {
+		case r == quote || r == '\\':
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			out.WriteString(fmt.Sprintf(`\x%02x`, r))
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteRune(quote)
+	return out.String()
+}
+
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	if len(t.Elements) == 1 {
+		return "(" + t.Elements[0].Inspect() + ",)"
+	}
+	return "(" + inspectAll(t.Elements) + ")"
+}
+
+// Slice is the index of a subscript like a[1:5:2]. Unset parts are None.
+type Slice struct {
+	Start Object
+	Stop  Object
+	Step  Object
+}
+
+func (s *Slice) Type() ObjectType { return SLICE_OBJ }
+func (s *Slice) Inspect() string {
+	return "slice(" + inspectAll([]Object{s.Start, s.Stop, s.Step}) + ")"
+}
+
+type List struct {
+	Elements []Object
+}
+
+func (l *List) Type() ObjectType { return LIST_OBJ }
+func (l *List) Inspect() string  { return "[" + inspectAll(l.Elements) + "]" }
+
+func inspectAll(objs []Object) string {
+	parts := make([]string, len(objs))
+	for i, obj := range objs {
+		parts[i] = obj.Inspect()
+	}
+	return strings.Join(parts, ", ")
+}
+
+type Function struct {
+	Name        string
+	Params      []string
+	Defaults    []Object // Parallel to Params, nil where there is no default
+	KwOnly      int      // How many of the trailing Params are keyword-only
+	VarArgs     string   // Name of the *args parameter, if any
+	KwArgs      string   // Name of the **kwargs parameter, if any
+	Body        ast.Node
+	Env         *Environment
+	IsGenerator bool
+	Locals      map[string]bool   // Names the parameters and body bind
+	Attrs       map[string]Object // The function's __dict__, including __doc__
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string  { return "<function " + f.Name + ">" }
+
+type Keyword struct {
+	Name  string
+	Value Object
+}
+
+// Caller is the interpreter a builtin is called from. Builtins use it to call
+// back into Python code.
+type Caller interface {
+	Call(fn Object, args []Object, kwargs []Keyword) (Object, error)
+}
+
+type BuiltinFunction func(c Caller, args []Object, kwargs []Keyword) (Object, error)
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "<built-in function " + b.Name + ">" }
+
+type BoundMethod struct {
+	Self     Object
+	Function Object
+}
+
+func (m *BoundMethod) Type() ObjectType { return METHOD_OBJ }
+func (m *BoundMethod) Inspect() string {
+	name := "?"
+	switch fn := m.Function.(type) {
+	case *Function:
+		name = fn.Name
+	case *Builtin:
+		name = fn.Name
+	}
+	return "<bound method " + name + " of " + m.Self.Inspect() + ">"
+}
@@ -0,0 +1,53 @@
+package object
+
+import "fmt"
+
+type Range struct {
+	Start int64
+	Stop  int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+func (r *Range) Len() int64 {
+	if r.Step > 0 && r.Start < r.Stop {
+		return (r.Stop - r.Start + r.Step - 1) / r.Step
+	} else if r.Step < 0 && r.Start > r.Stop {
+		return (r.Start - r.Stop - r.Step - 1) / -r.Step
+	}
+	return 0
+}
+
+func (r *Range) Item(i int64) int64 {
+	return r.Start + i*r.Step
+}
+
+func (r *Range) Contains(n int64) bool {
+	if r.Step > 0 && (n < r.Start || n >= r.Stop) || r.Step < 0 && (n > r.Start || n <= r.Stop) {
+		return false
+	}
+	return (n-r.Start)%r.Step == 0
+}
+
+type RangeIterator struct {
+	Range *Range
+	index int64
+}
+
+func (it *RangeIterator) Type() ObjectType { return "range_iterator" }
+func (it *RangeIterator) Inspect() string  { return "<range_iterator object>" }
+
+func (it *RangeIterator) Next() (Object, error) {
+	if it.index >= it.Range.Len() {
+		return nil, nil
+	}
+	it.index++
+	return &Integer{Value: it.Range.Item(it.index - 1)}, nil
+}
@@ -0,0 +1,98 @@
+package object
+
+import "strings"
+
+// Set is an unordered collection of hashable objects. Elements are kept in
+// insertion order so output is deterministic.
+type Set struct {
+	items *Dict
+}
+
+func NewSet() *Set {
+	return &Set{items: NewDict()}
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+func (s *Set) Inspect() string {
+	if s.Len() == 0 {
+		return "set()"
+	}
+	parts := make([]string, s.Len())
+	for i := range parts {
+		parts[i] = s.Item(i).Inspect()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (s *Set) Len() int { return s.items.Len() }
+
+func (s *Set) Contains(key HashKey) bool {
+	_, ok := s.items.Get(key)
+	return ok
+}
+
+func (s *Set) Add(key HashKey, elem Object) {
+	s.items.Set(key, elem, NONE)
+}
+
+// AddHashed is Add for an element whose class defines __hash__, as for
+// Dict.SetHashed.
+func (s *Set) AddHashed(key HashKey, hash int64, elem Object) {
+	s.items.SetHashed(key, hash, elem, NONE)
+}
+
+// Hashed returns the elements added with AddHashed under hash.
+func (s *Set) Hashed(hash int64) []Object {
+	return s.items.Hashed(hash)
+}
+
+func (s *Set) Remove(key HashKey) bool {
+	_, ok := s.items.Delete(key)
+	return ok
+}
+
+func (s *Set) Clear() {
+	s.items.Clear()
+}
+
+// Item returns the i-th element in insertion order.
+func (s *Set) Item(i int) Object {
+	return s.items.PairAt(i).Key
+}
+
+func (s *Set) Elements() []Object {
+	elements := make([]Object, s.Len())
+	for i := range elements {
+		elements[i] = s.Item(i)
+	}
+	return elements
+}
+
+func (s *Set) Copy() *Set {
+	return &Set{items: s.items.Copy()}
+}
+
+type SetIterator struct {
+	Set   *Set
+	index int
+	size  int
+}
+
+func NewSetIterator(s *Set) *SetIterator {
+	return &SetIterator{Set: s, size: s.Len()}
+}
+
+func (it *SetIterator) Type() ObjectType { return "set_iterator" }
+func (it *SetIterator) Inspect() string  { return "<set_iterator object>" }
+
+func (it *SetIterator) Next() (Object, error) {
+	if it.Set.Len() != it.size {
+		it.size = -1
+		return nil, NewError(RuntimeError, "Set changed size during iteration")
+	}
+	if it.index >= it.size {
+		return nil, nil
+	}
+	it.index++
+	return it.Set.Item(it.index - 1), nil
+}
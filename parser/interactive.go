@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"errors"
+	"snek/ast"
+	"snek/lexer"
+	"snek/token"
+	"strings"
+)
+
+// ErrIncomplete is returned by ParseInteractive when the input is valid so
+// far but needs more lines.
+var ErrIncomplete = errors.New("incomplete input")
+
+// ParseExpression parses src as a single expression list, like the eval mode
+// of Python's compile. Lexer errors are returned as *lexer.Error and parse
+// errors as *ParseError, with a nil node.
+func ParseExpression(src string, opts ...Option) (ast.Node, error) {
+	p, lexErr := newFromSource(src, opts...)
+	if lexErr != nil {
+		return nil, lexErr
+	}
+
+	res, err := p.parseEvalInput()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseStatement parses src as a single statement, like the single mode of
+// Python's compile. Simple statements separated by semicolons count as one.
+// Errors are reported as by ParseExpression.
+func ParseStatement(src string, opts ...Option) (ast.Node, error) {
+	p, lexErr := newFromSource(src, opts...)
+	if lexErr != nil {
+		return nil, lexErr
+	}
+
+	res, err := p.parseSingleInput()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseInteractive parses src as a statement typed at a prompt. It returns
+// ErrIncomplete if more lines could complete it: inside brackets, after a
+// line continuation or a block header not yet followed by a blank line,
+// and, as in Python's REPL, after a compound statement until a blank line
+// ends it. Input without a statement gives an empty block. Other errors are
+// reported as by ParseExpression.
+func ParseInteractive(src string, opts ...Option) (ast.Node, error) {
+	p, lexErr := newFromSource(src, opts...)
+	if lexErr != nil {
+		if lexErr.Incomplete {
+			return nil, ErrIncomplete
+		}
+		return nil, lexErr
+	}
+
+	if p.curTokenIs(token.EOF) {
+		return &ast.BlockNode{Statements: []ast.Node{}}, nil
+	}
+
+	compound := p.isCompoundStatement()
+	res, err := p.parseSingleInput()
+	if err != nil {
+		if p.atEndOfInput() && !endsWithBlankLine(src) {
+			return nil, ErrIncomplete
+		}
+		return nil, err
+	}
+
+	if _, ok := res.(*ast.MatchNode); (compound || ok) && !endsWithBlankLine(src) {
+		return nil, ErrIncomplete
+	}
+	return res, nil
+}
+
+// newFromSource tokenizes src for a parser, returning the first lexer error.
+func newFromSource(src string, opts ...Option) (*Parser, *lexer.Error) {
+	var config Parser
+	for _, opt := range opts {
+		opt(&config)
+	}
+	l := lexer.New(src, config.lexerOpts...)
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return New(tokens, opts...), nil
+}
+
+func (p *Parser) parseEvalInput() (ast.Node, error) {
+	defer p.untrace(p.trace("evalInput"))
+	if p.curTokenIs(token.INDENT) {
+		p.nextToken()
+	}
+
+	res, err := p.parseStarExpressions()
+	if err != nil {
+		return res, err
+	}
+
+	for p.curTokenIs(token.NEW_LINE) || p.curTokenIs(token.DEDENT) {
+		p.nextToken()
+	}
+	if !p.curTokenIs(token.EOF) {
+		return res, p.curError(token.EOF)
+	}
+
+	return res, nil
+}
+
+func (p *Parser) parseSingleInput() (ast.Node, error) {
+	defer p.untrace(p.trace("singleInput"))
+	res, err := p.parseStatement()
+	if err != nil {
+		return res, err
+	}
+
+	if !p.curTokenIs(token.EOF) {
+		return res, &ParseError{Value: "multiple statements found while compiling a single statement"}
+	}
+
+	return res, nil
+}
+
+// atEndOfInput reports whether only the dedents closing the input are left.
+func (p *Parser) atEndOfInput() bool {
+	for _, tok := range p.tokens[min(p.pos-1, len(p.tokens)):] {
+		if tok.Type != token.DEDENT && tok.Type != token.EOF {
+			return false
+		}
+	}
+	return true
+}
+
+func endsWithBlankLine(src string) bool {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	return len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == ""
+}
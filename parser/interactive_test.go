@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"errors"
+	"snek/lexer"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		lexErr   bool
+	}{
+		{"1 + 2", "(1 + 2)", false},
+		{"  x, y\n", "(x, y)", false},
+		{"1 +", "", false},
+		{"x = 1", "", false},
+		{"1 2", "", false},
+		{"(1", "", true},
+		{"print(1))", "", true},
+	}
+
+	for _, tt := range tests {
+		res, err := ParseExpression(tt.input)
+		if tt.expected == "" {
+			if err == nil || res != nil {
+				t.Errorf("ParseExpression(%q) = %v, %v, want nil and an error", tt.input, res, err)
+			}
+			var lexErr *lexer.Error
+			if errors.As(err, &lexErr) != tt.lexErr {
+				t.Errorf("ParseExpression(%q) error %T, lexer error wanted: %v", tt.input, err, tt.lexErr)
+			}
+			continue
+		}
+		if err != nil || res.String() != tt.expected {
+			t.Errorf("ParseExpression(%q) = %v, %v, want %s", tt.input, res, err, tt.expected)
+		}
+	}
+}
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+	}{
+		{"x = 1", true},
+		{"x = 1; y = 2\n", true},
+		{"if x:\n    y\n", true},
+		{"x = 1\ny = 2\n", false},
+		{"x = (", false},
+		{"def f(x:\n", false},
+	}
+
+	for _, tt := range tests {
+		res, err := ParseStatement(tt.input)
+		if tt.ok && err != nil {
+			t.Errorf("ParseStatement(%q) failed: %v", tt.input, err)
+		}
+		if !tt.ok && (err == nil || res != nil) {
+			t.Errorf("ParseStatement(%q) = %v, %v, want nil and an error", tt.input, res, err)
+		}
+	}
+}
+
+func TestParseInteractive(t *testing.T) {
+	tests := []struct {
+		input      string
+		incomplete bool
+		err        bool
+	}{
+		{"", false, false},
+		{"# comment\n", false, false},
+		{"x = 1\n", false, false},
+		{"if x:\n", true, false},
+		{"if x:\n    y = 1\n", true, false},
+		{"if x:\n    y = 1\n\n", false, false},
+		{"if x:\n\n", false, true},
+		{"if x: pass\n", true, false},
+		{"class A:\n    def f(self):\n", true, false},
+		{"@dec\n", true, false},
+		{"f(1,\n", true, false},
+		{"x = \\\n", true, false},
+		{"match x:\n", true, false},
+		{"match = 3\n", false, false},
+		{"x = 1 +\n", false, true},
+		{"print(1))\n", false, true},
+	}
+
+	for _, tt := range tests {
+		res, err := ParseInteractive(tt.input)
+		switch {
+		case tt.incomplete:
+			if !errors.Is(err, ErrIncomplete) {
+				t.Errorf("ParseInteractive(%q) = %v, %v, want ErrIncomplete", tt.input, res, err)
+			}
+		case tt.err:
+			if err == nil || errors.Is(err, ErrIncomplete) || res != nil {
+				t.Errorf("ParseInteractive(%q) = %v, %v, want nil and a syntax error", tt.input, res, err)
+			}
+		case err != nil:
+			t.Errorf("ParseInteractive(%q) failed: %v", tt.input, err)
+		}
+	}
+}
+
+func TestLexerOptions(t *testing.T) {
+	input := "if x:\n\ta\n    b\n\n"
+	lax := WithLexerOptions(lexer.WithTabWidth(4), lexer.WithStrictTabs(false))
+
+	var lexErr *lexer.Error
+	if _, err := ParseStatement(input); !errors.As(err, &lexErr) || lexErr.Kind != "IndentationError" {
+		t.Errorf("ParseStatement(%q) gave %v, want an IndentationError", input, err)
+	}
+	if _, err := ParseStatement(input, lax); err != nil {
+		t.Errorf("ParseStatement(%q) with a tab width of 4 failed: %v", input, err)
+	}
+	if _, err := ParseInteractive(input, lax); err != nil {
+		t.Errorf("ParseInteractive(%q) with a tab width of 4 failed: %v", input, err)
+	}
+	if _, err := ParseExpression("[1,\n\t2]", lax); err != nil {
+		t.Errorf("ParseExpression with lexer options failed: %v", err)
+	}
+	if _, err := ParseStatement(input, WithLexerOptions(lexer.WithTabWidth(4))); !errors.As(err, &lexErr) || lexErr.Kind != "TabError" {
+		t.Errorf("ParseStatement(%q) with strict tabs gave %v, want a TabError", input, err)
+	}
+}
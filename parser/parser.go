@@ -2,8 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"snek/ast"
+	"snek/lexer"
 	"snek/token"
+	"strconv"
+	"strings"
 )
 
 type (
@@ -13,31 +17,19 @@ type (
 )
 
 const (
-	LOWEST int = iota
-	OR
-	AND
-	NOT
-	COMPARE
-	SUM
-	PRODUCT
-	PREFIX
-	EXP
-	ATTR
+	LOWEST      = token.PREC_LOWEST
+	CONDITIONAL = token.PREC_CONDITIONAL
+	OR          = token.PREC_OR
+	AND         = token.PREC_AND
+	NOT         = token.PREC_NOT
+	COMPARE     = token.PREC_COMPARE
+	SUM         = token.PREC_SUM
+	PRODUCT     = token.PREC_PRODUCT
+	PREFIX      = token.PREC_PREFIX
+	EXP         = token.PREC_EXP
+	ATTR        = token.PREC_ATTR
 )
 
-var precedences = map[token.TokenType]int{
-	token.OR:       OR,
-	token.AND:      AND,
-	token.NOT:      NOT,
-	token.COMPARE:  COMPARE,
-	token.SUM:      SUM,
-	token.PRODUCT:  PRODUCT,
-	token.EXP:      EXP,
-	token.LPAREN:   ATTR,
-	token.LBRACKET: ATTR,
-	token.DOT:      ATTR,
-}
-
 type ParseError struct {
 	Value string
 }
@@ -56,9 +48,45 @@ type Parser struct {
 	compundStatementFns map[token.TokenType]statementParseFn
 	prefixFns           map[token.TokenType]prefixParseFn
 	infixFns            map[token.TokenType]infixParseFn
+
+	functions []*functionScope // Enclosing functions, lambdas and class bodies, innermost last
+	loops     int              // Loops around the current statement at module level
+
+	// Targets of the assignment expressions parsed in the current scope. A
+	// comprehension is only recognized at its first 'for', after its element,
+	// so it checks the ones added since its start then.
+	namedExprs []string
+	yields     int // Yield expressions parsed in the current scope
+
+	tracer     func(TraceEvent)
+	traceLevel int
+
+	lexerOpts []lexer.Option // For the functions that parse source
+}
+
+type functionScope struct {
+	isGenerator bool
+	isClass     bool // Class bodies may not return or yield
+	loops       int  // Loops around the current statement in this scope
+	namedExprs  int  // Length of Parser.namedExprs on entry
+	yields      int  // Parser.yields on entry
 }
 
-func New(tokens []token.Token) *Parser {
+// comprehensionMark records where a possible comprehension starts.
+type comprehensionMark struct {
+	namedExprs int
+	yields     int
+}
+
+type Option func(*Parser)
+
+// WithLexerOptions sets the options ParseExpression, ParseStatement and
+// ParseInteractive tokenize source with.
+func WithLexerOptions(opts ...lexer.Option) Option {
+	return func(p *Parser) { p.lexerOpts = opts }
+}
+
+func New(tokens []token.Token, opts ...Option) *Parser {
 	p := &Parser{
 		tokens: tokens,
 		pos:    -1,
@@ -68,33 +96,56 @@ func New(tokens []token.Token) *Parser {
 		prefixFns:           make(map[token.TokenType]prefixParseFn),
 		infixFns:            make(map[token.TokenType]infixParseFn),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
 
 	p.simpleStatementFns[token.PASS] = p.parseControlStatement
 	p.simpleStatementFns[token.BREAK] = p.parseControlStatement
 	p.simpleStatementFns[token.CONTINUE] = p.parseControlStatement
 	p.simpleStatementFns[token.RETURN] = p.parseReturnStatement
-	p.simpleStatementFns[token.IMPORT] = nil
+	p.simpleStatementFns[token.IMPORT] = p.parseImportStatement
+	p.simpleStatementFns[token.FROM] = p.parseFromImportStatement
+	p.simpleStatementFns[token.ASSERT] = p.parseAssertStatement
+	p.simpleStatementFns[token.DEL] = p.parseDelStatement
+	p.simpleStatementFns[token.RAISE] = p.parseRaiseStatement
 	p.simpleStatementFns[token.GLOBAL] = nil // TODO: add nonlocal
 
 	p.compundStatementFns[token.DEF] = p.parseFunctionDef
 	p.compundStatementFns[token.IF] = p.parseIfStatement
 	p.compundStatementFns[token.FOR] = p.parseForStatement
 	p.compundStatementFns[token.WHILE] = p.parseWhileStatement
+	p.compundStatementFns[token.CLASS] = p.parseClassDef
+	p.compundStatementFns[token.WITH] = p.parseWithStatement
+	p.compundStatementFns[token.TRY] = p.parseTryStatement
+	p.compundStatementFns[token.AT] = p.parseDecorated
 
 	p.prefixFns[token.IDENTIFIER] = p.parseIdentifierPrefix
 	p.prefixFns[token.NUMBER] = p.parseNumberPrefix
+	p.prefixFns[token.STRING] = p.parseStringPrefix
 	p.prefixFns[token.LPAREN] = p.parseGroupPrefix
-	p.prefixFns[token.SUM] = p.parseExpressionPrefix
+	p.prefixFns[token.LBRACKET] = p.parseListPrefix
+	p.prefixFns[token.LBRACE] = p.parseDictPrefix
+	p.prefixFns[token.STAR] = p.parseStarredPrefix
+	p.prefixFns[token.PLUS] = p.parseExpressionPrefix
+	p.prefixFns[token.MINUS] = p.parseExpressionPrefix
+	p.prefixFns[token.NOT] = p.parseNotPrefix
+	p.prefixFns[token.YIELD] = p.parseYieldPrefix
+	p.prefixFns[token.LAMBDA] = p.parseLambdaPrefix
 
 	p.infixFns[token.OR] = p.parseExpressionInfix
 	p.infixFns[token.AND] = p.parseExpressionInfix
-	p.infixFns[token.COMPARE] = p.parseExpressionInfix
-	p.infixFns[token.SUM] = p.parseExpressionInfix
-	p.infixFns[token.PRODUCT] = p.parseExpressionInfix
-	p.infixFns[token.EXP] = p.parseExpressionInfix
+	for _, t := range []token.TokenType{token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE, token.IN} {
+		p.infixFns[t] = p.parseExpressionInfix
+	}
+	for _, t := range []token.TokenType{token.PLUS, token.MINUS, token.STAR, token.SLASH, token.DSLASH, token.PERCENT, token.DSTAR} {
+		p.infixFns[t] = p.parseExpressionInfix
+	}
+	p.infixFns[token.NOT] = p.parseNotInInfix
 	p.infixFns[token.DOT] = p.parseExpressionInfix
 	p.infixFns[token.LPAREN] = p.parseCallInfix
 	p.infixFns[token.LBRACKET] = p.parseSlicesInfix
+	p.infixFns[token.IF] = p.parseConditionalInfix
 
 	p.nextToken()
 	p.nextToken()
@@ -102,12 +153,12 @@ func New(tokens []token.Token) *Parser {
 }
 
 func (p *Parser) ParseFile() (ast.Node, error) {
-	defer untrace(trace("file"))
+	defer p.untrace(p.trace("file"))
 	return p.parseStatements(token.EOF)
 }
 
 func (p *Parser) parseBlock() (ast.Node, error) {
-	defer untrace(trace("block"))
+	defer p.untrace(p.trace("block"))
 	if p.curTokenIs(token.NEW_LINE) {
 		p.nextToken()
 
@@ -131,7 +182,7 @@ func (p *Parser) parseBlock() (ast.Node, error) {
 }
 
 func (p *Parser) parseStatements(endToken token.TokenType) (ast.Node, error) {
-	defer untrace(trace("statements"))
+	defer p.untrace(p.trace("statements"))
 	block := &ast.BlockNode{Statements: []ast.Node{}}
 
 	for !p.curTokenIs(endToken) {
@@ -147,7 +198,19 @@ func (p *Parser) parseStatements(endToken token.TokenType) (ast.Node, error) {
 }
 
 func (p *Parser) parseStatement() (ast.Node, error) {
-	defer untrace(trace("statement"))
+	defer p.untrace(p.trace("statement"))
+	// match is a soft keyword: fall back to a simple statement unless the
+	// line starts a match block.
+	if p.curTokenIsSoftKeyword("match") {
+		startPos := p.pos
+		if res, err := p.parseMatchStatement(); err == nil {
+			return res, nil
+		} else if _, ok := res.(*ast.MatchNode); ok {
+			return res, err
+		}
+		p.setPos(startPos)
+	}
+
 	if p.isCompoundStatement() {
 		return p.parseCompoundStatement()
 	} else {
@@ -156,7 +219,7 @@ func (p *Parser) parseStatement() (ast.Node, error) {
 }
 
 func (p *Parser) parseSimpleStatements() (ast.Node, error) {
-	defer untrace(trace("simpleStatements"))
+	defer p.untrace(p.trace("simpleStatements"))
 	block := &ast.BlockNode{Statements: []ast.Node{}}
 
 	for !p.curTokenIs(token.NEW_LINE) {
@@ -172,7 +235,9 @@ func (p *Parser) parseSimpleStatements() (ast.Node, error) {
 		p.nextToken()
 	}
 
-	p.nextToken()
+	if err := p.expect(token.NEW_LINE); err != nil {
+		return block, err
+	}
 
 	if len(block.Statements) == 0 {
 		return block, &ParseError{Value: "empty simple statements"}
@@ -182,65 +247,59 @@ func (p *Parser) parseSimpleStatements() (ast.Node, error) {
 }
 
 func (p *Parser) parseSimpleStatement() (ast.Node, error) {
-	defer untrace(trace("simpleStatement"))
+	defer p.untrace(p.trace("simpleStatement"))
 	stmtParsingFn := p.simpleStatementFns[p.curToken.Type]
 	if stmtParsingFn != nil {
 		return stmtParsingFn()
 	}
 
-	startPos := p.pos
-	if res, err := p.parseAssignmentStatement(); err == nil {
-		return res, nil
+	res, err := p.parseStarExpressions()
+	if err != nil {
+		return res, err
 	}
 
-	p.setPos(startPos)
-	return p.parseExpressions()
-}
-
-func (p *Parser) parseExpressions() (ast.Node, error) {
-	defer untrace(trace("expressions"))
-	n := &ast.ExpressionsNode{}
-
-	for {
-		startPos := p.pos
-		res, err := p.parseExpression(LOWEST)
-		if err != nil {
-			if len(n.Expressions) == 0 { // Must not be empty
-				return n, err
-			} else {
-				p.setPos(startPos)
-				break
-			}
-		}
-
-		n.Expressions = append(n.Expressions, res)
-
-		if p.curTokenIs(token.COMMA) {
-			p.nextToken()
-		}
+	// The first expression list is the target if an assignment follows.
+	if p.curTokenIs(token.ASSIGN) || isAugmentedAssign(p.curToken.Type) {
+		return p.parseAssignmentStatement(res)
 	}
 
-	return n, nil
+	return &ast.ExpressionsNode{Expressions: []ast.Node{res}}, nil
 }
 
 // Simple statement parsers
 
 func (p *Parser) parseControlStatement() (ast.Node, error) {
-	defer untrace(trace("controlStatement"))
+	defer p.untrace(p.trace("controlStatement"))
 	stmt := &ast.ControlNode{Type: p.curToken.Literal}
+	if *p.loopDepth() == 0 {
+		switch stmt.Type {
+		case "break":
+			return nil, &ParseError{Value: "'break' outside loop"}
+		case "continue":
+			return nil, &ParseError{Value: "'continue' not properly in loop"}
+		}
+	}
 	p.nextToken()
 	return stmt, nil
 }
 
 func (p *Parser) parseReturnStatement() (ast.Node, error) {
-	defer untrace(trace("returnStatement"))
+	defer p.untrace(p.trace("returnStatement"))
 
 	if err := p.expect(token.RETURN); err != nil {
 		return nil, err
 	}
 
+	if !p.inFunction() {
+		return nil, &ParseError{Value: "'return' outside function"}
+	}
+
 	stmt := &ast.ReturnNode{}
-	res, err := p.parseExpression(LOWEST)
+	if !p.canStartExpression() {
+		return stmt, nil
+	}
+
+	res, err := p.parseStarExpressions()
 	stmt.Value = res
 	if err != nil {
 		return stmt, err
@@ -249,385 +308,2116 @@ func (p *Parser) parseReturnStatement() (ast.Node, error) {
 	return stmt, nil
 }
 
-// Compound statement parsers
-
-func (p *Parser) parseFunctionDef() (ast.Node, error) {
-	if err := p.expect(token.DEF); err != nil {
+func (p *Parser) parseAssertStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("assertStatement"))
+	if err := p.expect(token.ASSERT); err != nil {
 		return nil, err
 	}
 
-	stmt := &ast.FunctionDefNode{}
-	res, err := p.parseIdentifierPrefix()
-	stmt.Name = res
+	stmt := &ast.AssertNode{}
+	res, err := p.parseExpression(LOWEST)
+	stmt.Test = res
 	if err != nil {
 		return stmt, err
 	}
 
-	if err := p.expect(token.LPAREN); err != nil {
-		return stmt, err
+	if p.curTokenIs(token.COMMA) {
+		p.nextToken()
+		res, err := p.parseExpression(LOWEST)
+		stmt.Message = res
+		if err != nil {
+			return stmt, err
+		}
 	}
 
-	params, err := p.parseParams()
-	stmt.Params = params
-	if err != nil {
-		return stmt, err
+	return stmt, nil
+}
+
+func (p *Parser) parseDelStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("delStatement"))
+	if err := p.expect(token.DEL); err != nil {
+		return nil, err
 	}
 
-	if err := p.expect(token.RPAREN); err != nil {
-		return stmt, err
+	stmt := &ast.DelNode{}
+	for {
+		res, err := p.parseTarget()
+		if err != nil {
+			return stmt, err
+		}
+		if err := checkDelTarget(res); err != nil {
+			return stmt, err
+		}
+		stmt.Targets = append(stmt.Targets, res)
+
+		if !p.curTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		if p.curTokenIs(token.NEW_LINE) || p.curTokenIs(token.SEMICOLON) {
+			break
+		}
 	}
 
-	if err := p.expect(token.COLON); err != nil {
-		return stmt, err
+	return stmt, nil
+}
+
+func (p *Parser) parseRaiseStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("raiseStatement"))
+	if err := p.expect(token.RAISE); err != nil {
+		return nil, err
 	}
 
-	res, err = p.parseBlock()
-	stmt.Body = res
+	stmt := &ast.RaiseNode{}
+	if p.curTokenIs(token.NEW_LINE) || p.curTokenIs(token.SEMICOLON) {
+		return stmt, nil
+	}
+
+	res, err := p.parseExpression(LOWEST)
+	stmt.Exception = res
 	if err != nil {
 		return stmt, err
 	}
 
+	if p.curTokenIs(token.FROM) {
+		p.nextToken()
+		res, err := p.parseExpression(LOWEST)
+		stmt.Cause = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
 	return stmt, nil
 }
 
-func (p *Parser) parseParams() ([]ast.Node, error) {
-	params := []ast.Node{}
-	requireDefault := false
+func (p *Parser) parseImportStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("importStatement"))
+	if err := p.expect(token.IMPORT); err != nil {
+		return nil, err
+	}
 
-	for !p.curTokenIs(token.RPAREN) {
-		res, err := p.parseParam(requireDefault)
+	stmt := &ast.ImportNode{}
+	for {
+		name, err := p.parseDottedName()
 		if err != nil {
-			return params, err
+			return stmt, err
 		}
 
-		params = append(params, res)
-		if res.DefaultValue != nil {
-			requireDefault = true
+		alias, err := p.parseAlias(name)
+		stmt.Names = append(stmt.Names, alias)
+		if err != nil {
+			return stmt, err
 		}
 
-		if p.curTokenIs(token.COMMA) {
-			p.nextToken()
-		} else {
+		if !p.curTokenIs(token.COMMA) {
 			break
 		}
+		p.nextToken()
 	}
 
-	return params, nil
+	return stmt, nil
 }
 
-func (p *Parser) parseParam(requireDefault bool) (*ast.ParamNode, error) {
-	n := &ast.ParamNode{}
-	res, err := p.parseIdentifierPrefix()
-	n.Name = res
-	if err != nil {
-		return n, err
+func (p *Parser) parseFromImportStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("fromImportStatement"))
+	if err := p.expect(token.FROM); err != nil {
+		return nil, err
 	}
 
-	if requireDefault && !p.curTokenIs(token.ASSIGN) {
-		return n, p.curError(token.ASSIGN)
+	stmt := &ast.ImportFromNode{}
+	for p.curTokenIs(token.DOT) {
+		stmt.Level++
+		p.nextToken()
 	}
 
-	if p.curTokenIs(token.ASSIGN) {
+	if stmt.Level == 0 || !p.curTokenIs(token.IMPORT) {
+		name, err := p.parseDottedName()
+		stmt.Module = name
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	if err := p.expect(token.IMPORT); err != nil {
+		return stmt, err
+	}
+
+	if p.curTokenIs(token.STAR) {
 		p.nextToken()
-		res, err := p.parseExpression(LOWEST)
-		n.DefaultValue = res
+		stmt.Names = []*ast.AliasNode{{Name: "*"}}
+		return stmt, nil
+	}
+
+	parenthesized := p.curTokenIs(token.LPAREN)
+	if parenthesized {
+		p.nextToken()
+	}
+
+	for {
+		name, err := p.parseIdentifierPrefix()
 		if err != nil {
-			return n, err
+			return stmt, err
+		}
+
+		alias, err := p.parseAlias(name.String())
+		stmt.Names = append(stmt.Names, alias)
+		if err != nil {
+			return stmt, err
+		}
+
+		if !p.curTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		if parenthesized && p.curTokenIs(token.RPAREN) {
+			break
 		}
 	}
 
-	return n, nil
+	if parenthesized {
+		if err := p.expect(token.RPAREN); err != nil {
+			return stmt, err
+		}
+	}
+
+	return stmt, nil
 }
 
-func (p *Parser) parseCompoundStatement() (ast.Node, error) {
-	defer untrace(trace("compoundStatement"))
-	stmtParsingFn := p.compundStatementFns[p.curToken.Type]
-	if stmtParsingFn == nil {
-		return nil, &ParseError{Value: fmt.Sprintf("no statement parse function for %s", p.curToken.Type)}
+// parseDottedName parses a module path such as a.b.c.
+func (p *Parser) parseDottedName() (string, error) {
+	defer p.untrace(p.trace("dottedName"))
+	parts := []string{}
+	for {
+		res, err := p.parseIdentifierPrefix()
+		if err != nil {
+			return strings.Join(parts, "."), err
+		}
+		parts = append(parts, res.String())
+
+		if !p.curTokenIs(token.DOT) {
+			return strings.Join(parts, "."), nil
+		}
+		p.nextToken()
 	}
-	return stmtParsingFn()
 }
 
-func (p *Parser) parseIfStatement() (ast.Node, error) {
-	defer untrace(trace("ifStatement"))
-	return p.parseIfElifStatement(false)
+func (p *Parser) parseAlias(name string) (*ast.AliasNode, error) {
+	alias := &ast.AliasNode{Name: name}
+	if !p.curTokenIs(token.AS) {
+		return alias, nil
+	}
+
+	p.nextToken()
+	res, err := p.parseIdentifierPrefix()
+	if err != nil {
+		return alias, err
+	}
+	alias.AsName = res.String()
+	return alias, nil
 }
 
-func (p *Parser) parseIfElifStatement(isElif bool) (ast.Node, error) {
-	defer untrace(trace("ifElifStatement"))
-	stmt := &ast.IfNode{}
+// Compound statement parsers
 
-	startToken := token.IF
-	if isElif {
-		startToken = token.ELIF
+func (p *Parser) parseFunctionDef() (ast.Node, error) {
+	if err := p.expect(token.DEF); err != nil {
+		return nil, err
 	}
 
-	if err := p.expect(startToken); err != nil {
+	stmt := &ast.FunctionDefNode{}
+	res, err := p.parseIdentifierPrefix()
+	stmt.Name = res
+	if err != nil {
 		return stmt, err
 	}
 
-	res, err := p.parseExpression(LOWEST)
-	stmt.Condition = res
+	if err := p.expect(token.LPAREN); err != nil {
+		return stmt, err
+	}
+
+	params, err := p.parseParams(token.RPAREN)
+	stmt.Params = params
 	if err != nil {
 		return stmt, err
 	}
 
+	if err := p.expect(token.RPAREN); err != nil {
+		return stmt, err
+	}
+
 	if err := p.expect(token.COLON); err != nil {
 		return stmt, err
 	}
 
+	scope := p.enterFunction()
 	res, err = p.parseBlock()
+	p.leaveFunction()
 	stmt.Body = res
+	stmt.IsGenerator = scope.isGenerator
 	if err != nil {
 		return stmt, err
 	}
 
-	if p.curTokenIs(token.ELIF) {
-		res, err := p.parseIfElifStatement(true)
-		stmt.Else = res
-		if err != nil {
-			return stmt, err
-		}
-	} else if p.curTokenIs(token.ELSE) {
-		res, err := p.parseElseBlock()
-		stmt.Else = res
-		if err != nil {
-			return stmt, err
-		}
-	}
-
 	return stmt, nil
 }
 
-func (p *Parser) parseWhileStatement() (ast.Node, error) {
-	defer untrace(trace("whileStatement"))
-	stmt := &ast.WhileNode{}
-
-	if err := p.expect(token.WHILE); err != nil {
-		return stmt, err
+func (p *Parser) parseClassDef() (ast.Node, error) {
+	defer p.untrace(p.trace("classDef"))
+	if err := p.expect(token.CLASS); err != nil {
+		return nil, err
 	}
 
-	res, err := p.parseExpression(LOWEST)
-	stmt.Condition = res
+	stmt := &ast.ClassDefNode{}
+	res, err := p.parseIdentifierPrefix()
+	stmt.Name = res
 	if err != nil {
 		return stmt, err
 	}
 
+	if p.curTokenIs(token.LPAREN) {
+		p.nextToken()
+		bases, err := p.parseArgs()
+		stmt.Bases = bases
+		if err != nil {
+			return stmt, err
+		}
+
+		if err := p.expect(token.RPAREN); err != nil {
+			return stmt, err
+		}
+	}
+
 	if err := p.expect(token.COLON); err != nil {
 		return stmt, err
 	}
 
+	p.enterClass()
 	res, err = p.parseBlock()
+	p.leaveFunction()
 	stmt.Body = res
 	if err != nil {
 		return stmt, err
 	}
 
-	if p.curTokenIs(token.ELSE) {
-		res, err := p.parseElseBlock()
-		stmt.Else = res
-		if err != nil {
-			return stmt, err
-		}
-	}
-
 	return stmt, nil
 }
 
-func (p *Parser) parseElseBlock() (ast.Node, error) {
-	defer untrace(trace("elseBlock"))
-	if err := p.expect(token.ELSE); err != nil {
-		return nil, err
+// parseDecorated parses the decorator lines in front of a def or class.
+func (p *Parser) parseDecorated() (ast.Node, error) {
+	defer p.untrace(p.trace("decorated"))
+	decorators := []ast.Node{}
+
+	for p.curTokenIs(token.AT) {
+		p.nextToken()
+		res, err := p.parseNamedExpression()
+		if err != nil {
+			return nil, err
+		}
+		decorators = append(decorators, res)
+
+		if err := p.expect(token.NEW_LINE); err != nil {
+			return nil, err
+		}
+	}
+
+	switch p.curToken.Type {
+	case token.DEF:
+		res, err := p.parseFunctionDef()
+		if stmt, ok := res.(*ast.FunctionDefNode); ok {
+			stmt.Decorators = decorators
+		}
+		return res, err
+	case token.CLASS:
+		res, err := p.parseClassDef()
+		if stmt, ok := res.(*ast.ClassDefNode); ok {
+			stmt.Decorators = decorators
+		}
+		return res, err
+	}
+
+	return nil, p.curError(token.DEF)
+}
+
+func (p *Parser) enterFunction() *functionScope {
+	scope := &functionScope{namedExprs: len(p.namedExprs), yields: p.yields}
+	p.functions = append(p.functions, scope)
+	return scope
+}
+
+func (p *Parser) leaveFunction() {
+	scope := p.functions[len(p.functions)-1]
+	p.namedExprs = p.namedExprs[:scope.namedExprs]
+	p.yields = scope.yields
+	p.functions = p.functions[:len(p.functions)-1]
+}
+
+// enterClass starts the scope of a class body, which is left with leaveFunction.
+func (p *Parser) enterClass() {
+	p.functions = append(p.functions, &functionScope{isClass: true, namedExprs: len(p.namedExprs), yields: p.yields})
+}
+
+// inClass reports whether the current statement is directly in a class body.
+func (p *Parser) inClass() bool {
+	return len(p.functions) > 0 && p.functions[len(p.functions)-1].isClass
+}
+
+func (p *Parser) markComprehension() comprehensionMark {
+	return comprehensionMark{namedExprs: len(p.namedExprs), yields: p.yields}
+}
+
+// inFunction reports whether the current statement is in a function or lambda body.
+func (p *Parser) inFunction() bool {
+	return len(p.functions) > 0 && !p.functions[len(p.functions)-1].isClass
+}
+
+// loopDepth returns the loop counter of the innermost scope. Loops do not
+// reach into the functions and classes defined inside them.
+func (p *Parser) loopDepth() *int {
+	if len(p.functions) == 0 {
+		return &p.loops
+	}
+	return &p.functions[len(p.functions)-1].loops
+}
+
+// parseLoopBody parses the body of a for or while loop, where break and
+// continue are allowed. The else block is not part of the body.
+func (p *Parser) parseLoopBody() (ast.Node, error) {
+	*p.loopDepth()++
+	defer func() { *p.loopDepth()-- }()
+	return p.parseBlock()
+}
+
+func (p *Parser) parseParams(endToken token.TokenType) ([]ast.Node, error) {
+	params := []ast.Node{}
+	requireDefault := false
+	keywordOnly := false
+
+	for !p.curTokenIs(endToken) {
+		res, err := p.parseParam(requireDefault && !keywordOnly)
+		if err != nil {
+			return params, err
+		}
+
+		switch res.Prefix {
+		case "*":
+			if keywordOnly {
+				return params, &ParseError{Value: "* argument may appear only once"}
+			}
+			keywordOnly = true
+		case "**":
+			if p.curTokenIs(token.COMMA) {
+				p.nextToken()
+			}
+			if !p.curTokenIs(endToken) {
+				return params, &ParseError{Value: "arguments cannot follow var-keyword argument"}
+			}
+		}
+
+		params = append(params, res)
+		if res.DefaultValue != nil {
+			requireDefault = true
+		}
+
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
+		}
+	}
+
+	for i, param := range params {
+		if param := param.(*ast.ParamNode); param.Prefix != "*" || param.Name != nil {
+			continue
+		}
+		if i == len(params)-1 || params[i+1].(*ast.ParamNode).Prefix != "" {
+			return params, &ParseError{Value: "named arguments must follow bare *"}
+		}
+	}
+
+	return params, nil
+}
+
+func (p *Parser) parseParam(requireDefault bool) (*ast.ParamNode, error) {
+	n := &ast.ParamNode{}
+	if p.curTokenIs(token.DSTAR) || p.curTokenIs(token.STAR) {
+		n.Prefix = p.curToken.Literal
+		p.nextToken()
+
+		if n.Prefix == "*" && !p.curTokenIs(token.IDENTIFIER) {
+			return n, nil
+		}
+	}
+
+	res, err := p.parseIdentifierPrefix()
+	n.Name = res
+	if err != nil {
+		return n, err
+	}
+
+	if n.Prefix != "" {
+		if p.curTokenIs(token.ASSIGN) {
+			return n, &ParseError{Value: "var-positional and var-keyword arguments cannot have default value"}
+		}
+		return n, nil
+	}
+
+	if requireDefault && !p.curTokenIs(token.ASSIGN) {
+		return n, p.curError(token.ASSIGN)
+	}
+
+	if p.curTokenIs(token.ASSIGN) {
+		p.nextToken()
+		res, err := p.parseExpression(LOWEST)
+		n.DefaultValue = res
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseCompoundStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("compoundStatement"))
+	stmtParsingFn := p.compundStatementFns[p.curToken.Type]
+	if stmtParsingFn == nil {
+		return nil, &ParseError{Value: fmt.Sprintf("no statement parse function for %s", p.curToken.Type)}
+	}
+	return stmtParsingFn()
+}
+
+func (p *Parser) parseIfStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("ifStatement"))
+	return p.parseIfElifStatement(false)
+}
+
+func (p *Parser) parseIfElifStatement(isElif bool) (ast.Node, error) {
+	defer p.untrace(p.trace("ifElifStatement"))
+	stmt := &ast.IfNode{}
+
+	startToken := token.IF
+	if isElif {
+		startToken = token.ELIF
+	}
+
+	if err := p.expect(startToken); err != nil {
+		return stmt, err
+	}
+
+	res, err := p.parseNamedExpression()
+	stmt.Condition = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return stmt, err
+	}
+
+	res, err = p.parseBlock()
+	stmt.Body = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if p.curTokenIs(token.ELIF) {
+		res, err := p.parseIfElifStatement(true)
+		stmt.Else = res
+		if err != nil {
+			return stmt, err
+		}
+	} else if p.curTokenIs(token.ELSE) {
+		res, err := p.parseElseBlock()
+		stmt.Else = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseWhileStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("whileStatement"))
+	stmt := &ast.WhileNode{}
+
+	if err := p.expect(token.WHILE); err != nil {
+		return stmt, err
+	}
+
+	res, err := p.parseNamedExpression()
+	stmt.Condition = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return stmt, err
+	}
+
+	res, err = p.parseLoopBody()
+	stmt.Body = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if p.curTokenIs(token.ELSE) {
+		res, err := p.parseElseBlock()
+		stmt.Else = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseElseBlock() (ast.Node, error) {
+	defer p.untrace(p.trace("elseBlock"))
+	if err := p.expect(token.ELSE); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return nil, err
+	}
+
+	return p.parseBlock()
+}
+
+func (p *Parser) parseForStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("forStatement"))
+	if err := p.expect(token.FOR); err != nil {
+		return nil, err
+	}
+
+	stmt := &ast.ForNode{}
+	res, err := p.parseTargets()
+	stmt.Targets = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if err := p.expect(token.IN); err != nil {
+		return stmt, err
+	}
+
+	res, err = p.parseStarExpressions()
+	stmt.Values = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return nil, err
+	}
+
+	res, err = p.parseLoopBody()
+	stmt.Body = res
+	if err != nil {
+		return stmt, err
+	}
+
+	if p.curTokenIs(token.ELSE) {
+		res, err := p.parseElseBlock()
+		stmt.Else = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseWithStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("withStatement"))
+	if err := p.expect(token.WITH); err != nil {
+		return nil, err
+	}
+
+	stmt := &ast.WithNode{}
+	for {
+		item := &ast.WithItemNode{}
+		res, err := p.parseExpression(LOWEST)
+		item.Context = res
+		if err != nil {
+			return stmt, err
+		}
+
+		if p.curTokenIs(token.AS) {
+			p.nextToken()
+			res, err := p.parseTarget()
+			item.Target = res
+			if err != nil {
+				return stmt, err
+			}
+		}
+		stmt.Items = append(stmt.Items, item)
+
+		if !p.curTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return stmt, err
+	}
+
+	res, err := p.parseBlock()
+	stmt.Body = res
+	if err != nil {
+		return stmt, err
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseTryStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("tryStatement"))
+	if err := p.expect(token.TRY); err != nil {
+		return nil, err
+	}
+	if err := p.expect(token.COLON); err != nil {
+		return nil, err
+	}
+
+	stmt := &ast.TryNode{}
+	res, err := p.parseBlock()
+	stmt.Body = res
+	if err != nil {
+		return stmt, err
+	}
+
+	for p.curTokenIs(token.EXCEPT) {
+		if len(stmt.Handlers) > 0 && stmt.Handlers[len(stmt.Handlers)-1].Type == nil {
+			return stmt, &ParseError{Value: "default 'except:' must be last"}
+		}
+
+		handler, err := p.parseExceptHandler()
+		if handler != nil {
+			stmt.Handlers = append(stmt.Handlers, handler)
+		}
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	if p.curTokenIs(token.ELSE) {
+		if len(stmt.Handlers) == 0 {
+			return stmt, &ParseError{Value: "expected 'except' or 'finally' block"}
+		}
+		res, err := p.parseElseBlock()
+		stmt.Else = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	if p.curTokenIs(token.FINALLY) {
+		p.nextToken()
+		if err := p.expect(token.COLON); err != nil {
+			return stmt, err
+		}
+		res, err := p.parseBlock()
+		stmt.Finally = res
+		if err != nil {
+			return stmt, err
+		}
+	}
+
+	if len(stmt.Handlers) == 0 && stmt.Finally == nil {
+		return stmt, &ParseError{Value: "expected 'except' or 'finally' block"}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseExceptHandler() (*ast.ExceptHandlerNode, error) {
+	defer p.untrace(p.trace("exceptHandler"))
+	if err := p.expect(token.EXCEPT); err != nil {
+		return nil, err
+	}
+
+	handler := &ast.ExceptHandlerNode{}
+	if !p.curTokenIs(token.COLON) {
+		res, err := p.parseExpression(LOWEST)
+		handler.Type = res
+		if err != nil {
+			return handler, err
+		}
+
+		if p.curTokenIs(token.COMMA) {
+			return handler, &ParseError{Value: "multiple exception types must be parenthesized"}
+		}
+
+		if p.curTokenIs(token.AS) {
+			p.nextToken()
+			if !p.curTokenIs(token.IDENTIFIER) {
+				return handler, p.curError(token.IDENTIFIER)
+			}
+			handler.Name = &ast.IdentifierNode{Name: p.curToken.Literal}
+			p.nextToken()
+		}
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return handler, err
+	}
+
+	res, err := p.parseBlock()
+	handler.Body = res
+	if err != nil {
+		return handler, err
+	}
+
+	return handler, nil
+}
+
+// parseMatchStatement returns an *ast.MatchNode alongside any error found
+// after 'match subject:', so callers know not to retry as another statement.
+func (p *Parser) parseMatchStatement() (ast.Node, error) {
+	defer p.untrace(p.trace("matchStatement"))
+	p.nextToken()
+
+	subject, err := p.parseNamedExpression()
+	if err != nil {
+		return nil, err
+	}
+	subject, err = p.parseTupleRest(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return nil, err
+	}
+
+	stmt := &ast.MatchNode{Subject: subject}
+	if err := p.expect(token.NEW_LINE); err != nil {
+		return stmt, err
+	}
+	if err := p.expect(token.INDENT); err != nil {
+		return stmt, err
+	}
+	if !p.curTokenIsSoftKeyword("case") {
+		return stmt, &ParseError{Value: "expected 'case' block"}
+	}
+
+	for p.curTokenIsSoftKeyword("case") {
+		c, err := p.parseCaseBlock()
+		if err != nil {
+			return stmt, err
+		}
+		stmt.Cases = append(stmt.Cases, c)
+	}
+
+	if err := p.expect(token.DEDENT); err != nil {
+		return stmt, err
+	}
+
+	for i, c := range stmt.Cases {
+		if err := checkReachable(c.Pattern, c.Guard != nil || i == len(stmt.Cases)-1); err != nil {
+			return stmt, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseCaseBlock() (*ast.MatchCaseNode, error) {
+	defer p.untrace(p.trace("caseBlock"))
+	p.nextToken()
+
+	c := &ast.MatchCaseNode{}
+	res, err := p.parsePatterns()
+	c.Pattern = res
+	if err != nil {
+		return c, err
+	}
+
+	if _, err := patternNames(c.Pattern); err != nil {
+		return c, err
+	}
+
+	if p.curTokenIs(token.IF) {
+		p.nextToken()
+		res, err := p.parseNamedExpression()
+		c.Guard = res
+		if err != nil {
+			return c, err
+		}
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return c, err
+	}
+
+	res, err = p.parseBlock()
+	c.Body = res
+	if err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// parsePatterns parses the pattern of a case, where a bare comma separated
+// list is a sequence pattern.
+func (p *Parser) parsePatterns() (ast.Node, error) {
+	defer p.untrace(p.trace("patterns"))
+	first, err := p.parseMaybeStarPattern()
+	if err != nil {
+		return first, err
+	}
+
+	if !p.curTokenIs(token.COMMA) {
+		if _, ok := first.(*ast.MatchStarNode); ok {
+			return first, &ParseError{Value: "can't use starred pattern here"}
+		}
+		return first, nil
+	}
+
+	seq := &ast.MatchSequenceNode{Patterns: []ast.Node{first}}
+	for p.curTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.curTokenIs(token.COLON) || p.curTokenIs(token.IF) {
+			break
+		}
+
+		res, err := p.parseMaybeStarPattern()
+		if err != nil {
+			return seq, err
+		}
+		seq.Patterns = append(seq.Patterns, res)
+	}
+
+	return seq, checkStarPatterns(seq)
+}
+
+func (p *Parser) parseMaybeStarPattern() (ast.Node, error) {
+	if !p.curTokenIs(token.STAR) {
+		return p.parsePattern()
+	}
+
+	p.nextToken()
+	if !p.curTokenIs(token.IDENTIFIER) {
+		return nil, p.curError(token.IDENTIFIER)
+	}
+
+	n := &ast.MatchStarNode{}
+	if p.curToken.Literal != "_" {
+		n.Name = &ast.IdentifierNode{Name: p.curToken.Literal}
+	}
+	p.nextToken()
+	return n, nil
+}
+
+func (p *Parser) parsePattern() (ast.Node, error) {
+	defer p.untrace(p.trace("pattern"))
+	res, err := p.parseOrPattern()
+	if err != nil || !p.curTokenIs(token.AS) {
+		return res, err
+	}
+
+	p.nextToken()
+	if !p.curTokenIs(token.IDENTIFIER) {
+		return res, &ParseError{Value: "invalid pattern target"}
+	}
+	if p.curToken.Literal == "_" {
+		return res, &ParseError{Value: "cannot use '_' as a target"}
+	}
+
+	n := &ast.MatchAsNode{Pattern: res, Name: &ast.IdentifierNode{Name: p.curToken.Literal}}
+	p.nextToken()
+	return n, nil
+}
+
+func (p *Parser) parseOrPattern() (ast.Node, error) {
+	defer p.untrace(p.trace("orPattern"))
+	res, err := p.parseClosedPattern()
+	if err != nil || !p.curTokenIs(token.VBAR) {
+		return res, err
+	}
+
+	n := &ast.MatchOrNode{Patterns: []ast.Node{res}}
+	for p.curTokenIs(token.VBAR) {
+		p.nextToken()
+		res, err := p.parseClosedPattern()
+		if err != nil {
+			return n, err
+		}
+		n.Patterns = append(n.Patterns, res)
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseClosedPattern() (ast.Node, error) {
+	defer p.untrace(p.trace("closedPattern"))
+	switch p.curToken.Type {
+	case token.NUMBER:
+		res, err := p.parseNumberPrefix()
+		return &ast.MatchValueNode{Value: res}, err
+	case token.STRING:
+		res, err := p.parseStringPrefix()
+		return &ast.MatchValueNode{Value: res}, err
+	case token.PLUS, token.MINUS:
+		if !p.curTokenIs(token.MINUS) || p.peekToken.Type != token.NUMBER {
+			return nil, &ParseError{Value: "invalid pattern"}
+		}
+		p.nextToken()
+		res, err := p.parseNumberPrefix()
+		return &ast.MatchValueNode{Value: &ast.PrefixNode{Operator: token.MINUS, Right: res}}, err
+	case token.IDENTIFIER:
+		return p.parseNamePattern()
+	case token.LPAREN:
+		return p.parseGroupPattern()
+	case token.LBRACKET:
+		p.nextToken()
+		return p.parseSequencePattern(token.RBRACKET)
+	case token.LBRACE:
+		return p.parseMappingPattern()
+	}
+
+	return nil, &ParseError{Value: fmt.Sprintf("invalid pattern starting with %s", p.curToken.Type)}
+}
+
+// parseNamePattern parses the patterns starting with a name: singletons,
+// captures, the wildcard, dotted values and class patterns.
+func (p *Parser) parseNamePattern() (ast.Node, error) {
+	defer p.untrace(p.trace("namePattern"))
+	name := p.curToken.Literal
+	var res ast.Node = &ast.IdentifierNode{Name: name}
+	p.nextToken()
+
+	dotted := false
+	for p.curTokenIs(token.DOT) {
+		p.nextToken()
+		attr, err := p.parseIdentifierPrefix()
+		if err != nil {
+			return res, err
+		}
+		res = &ast.InfixNode{Left: res, Operator: token.DOT, Right: attr}
+		dotted = true
+	}
+
+	switch {
+	case p.curTokenIs(token.LPAREN):
+		return p.parseClassPattern(res)
+	case dotted:
+		return &ast.MatchValueNode{Value: res}, nil
+	case name == "None" || name == "True" || name == "False":
+		return &ast.MatchSingletonNode{Value: res}, nil
+	case name == "_":
+		return &ast.MatchAsNode{}, nil
+	}
+	return &ast.MatchAsNode{Name: res}, nil
+}
+
+// parseGroupPattern parses a parenthesized pattern, which is a sequence
+// pattern if it is empty or contains a comma.
+func (p *Parser) parseGroupPattern() (ast.Node, error) {
+	defer p.untrace(p.trace("groupPattern"))
+	p.nextToken()
+	if p.curTokenIs(token.RPAREN) {
+		p.nextToken()
+		return &ast.MatchSequenceNode{Patterns: []ast.Node{}}, nil
+	}
+
+	first, err := p.parseMaybeStarPattern()
+	if err != nil {
+		return first, err
+	}
+
+	if _, ok := first.(*ast.MatchStarNode); ok || p.curTokenIs(token.COMMA) {
+		return p.parseSequencePatternRest([]ast.Node{first}, token.RPAREN)
+	}
+
+	return first, p.expect(token.RPAREN)
+}
+
+func (p *Parser) parseSequencePattern(endToken token.TokenType) (ast.Node, error) {
+	return p.parseSequencePatternRest([]ast.Node{}, endToken)
+}
+
+func (p *Parser) parseSequencePatternRest(patterns []ast.Node, endToken token.TokenType) (ast.Node, error) {
+	defer p.untrace(p.trace("sequencePattern"))
+	seq := &ast.MatchSequenceNode{Patterns: patterns}
+
+	if len(patterns) > 0 && p.curTokenIs(token.COMMA) {
+		p.nextToken()
+	}
+
+	for !p.curTokenIs(endToken) {
+		res, err := p.parseMaybeStarPattern()
+		if err != nil {
+			return seq, err
+		}
+		seq.Patterns = append(seq.Patterns, res)
+
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
+		}
+	}
+
+	if err := p.expect(endToken); err != nil {
+		return seq, err
+	}
+	return seq, checkStarPatterns(seq)
+}
+
+func (p *Parser) parseMappingPattern() (ast.Node, error) {
+	defer p.untrace(p.trace("mappingPattern"))
+	p.nextToken()
+	n := &ast.MatchMappingNode{}
+
+	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.DSTAR) {
+			p.nextToken()
+			res, err := p.parseIdentifierPrefix()
+			n.Rest = res
+			if err != nil {
+				return n, err
+			}
+			if p.curTokenIs(token.COMMA) {
+				p.nextToken()
+			}
+			if !p.curTokenIs(token.RBRACE) {
+				return n, &ParseError{Value: "the ** pattern must come last in a mapping pattern"}
+			}
+			break
+		}
+
+		key, err := p.parseClosedPattern()
+		if err != nil {
+			return n, err
+		}
+		switch k := key.(type) {
+		case *ast.MatchValueNode:
+			n.Keys = append(n.Keys, k.Value)
+		case *ast.MatchSingletonNode:
+			n.Keys = append(n.Keys, k.Value)
+		default:
+			return n, &ParseError{Value: "mapping pattern keys may only match literals and attribute lookups"}
+		}
+
+		if err := p.expect(token.COLON); err != nil {
+			return n, err
+		}
+
+		res, err := p.parsePattern()
+		if err != nil {
+			return n, err
+		}
+		n.Patterns = append(n.Patterns, res)
+
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
+		}
+	}
+
+	return n, p.expect(token.RBRACE)
+}
+
+func (p *Parser) parseClassPattern(class ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("classPattern"))
+	p.nextToken()
+	n := &ast.MatchClassNode{Class: class}
+
+	for !p.curTokenIs(token.RPAREN) {
+		if p.curTokenIs(token.IDENTIFIER) && p.peekToken.Type == token.ASSIGN {
+			attr := p.curToken.Literal
+			for _, other := range n.KwdAttrs {
+				if other.String() == attr {
+					return n, &ParseError{Value: "attribute name repeated in class pattern: " + attr}
+				}
+			}
+			n.KwdAttrs = append(n.KwdAttrs, &ast.IdentifierNode{Name: attr})
+			p.nextToken()
+			p.nextToken()
+
+			res, err := p.parsePattern()
+			if err != nil {
+				return n, err
+			}
+			n.KwdPatterns = append(n.KwdPatterns, res)
+		} else {
+			if len(n.KwdAttrs) > 0 {
+				return n, &ParseError{Value: "positional patterns follow keyword patterns"}
+			}
+			res, err := p.parsePattern()
+			if err != nil {
+				return n, err
+			}
+			n.Patterns = append(n.Patterns, res)
+		}
+
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
+		}
+	}
+
+	return n, p.expect(token.RPAREN)
+}
+
+func checkStarPatterns(seq *ast.MatchSequenceNode) error {
+	stars := 0
+	for _, pattern := range seq.Patterns {
+		if _, ok := pattern.(*ast.MatchStarNode); ok {
+			stars++
+		}
+	}
+	if stars > 1 {
+		return &ParseError{Value: "multiple starred names in sequence pattern"}
+	}
+	return nil
+}
+
+// checkReachable rejects capture and wildcard patterns that would make the
+// patterns after them unreachable.
+func checkReachable(pattern ast.Node, allowIrrefutable bool) error {
+	switch n := pattern.(type) {
+	case *ast.MatchAsNode:
+		if n.Pattern != nil {
+			return checkReachable(n.Pattern, allowIrrefutable)
+		}
+		if allowIrrefutable {
+			return nil
+		}
+		if n.Name == nil {
+			return &ParseError{Value: "wildcard makes remaining patterns unreachable"}
+		}
+		return &ParseError{Value: fmt.Sprintf("name capture '%s' makes remaining patterns unreachable", n.Name)}
+	case *ast.MatchOrNode:
+		for i, alt := range n.Patterns {
+			if err := checkReachable(alt, allowIrrefutable && i == len(n.Patterns)-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// patternNames lists the names a pattern binds. Every name may only be bound
+// once, and all alternatives of an OR pattern must bind the same names.
+func patternNames(pattern ast.Node) ([]string, error) {
+	names := []string{}
+	add := func(patterns ...ast.Node) error {
+		for _, pattern := range patterns {
+			sub, err := patternNames(pattern)
+			if err != nil {
+				return err
+			}
+			names = append(names, sub...)
+		}
+		return nil
+	}
+
+	var err error
+	switch n := pattern.(type) {
+	case *ast.MatchAsNode:
+		if n.Pattern != nil {
+			err = add(n.Pattern)
+		}
+		if n.Name != nil {
+			names = append(names, n.Name.String())
+		}
+	case *ast.MatchStarNode:
+		if n.Name != nil {
+			names = append(names, n.Name.String())
+		}
+	case *ast.MatchSequenceNode:
+		err = add(n.Patterns...)
+	case *ast.MatchMappingNode:
+		err = add(n.Patterns...)
+		if n.Rest != nil {
+			names = append(names, n.Rest.String())
+		}
+	case *ast.MatchClassNode:
+		err = add(append(slices.Clone(n.Patterns), n.KwdPatterns...)...)
+	case *ast.MatchOrNode:
+		var first []string
+		for i, alt := range n.Patterns {
+			alts, err := patternNames(alt)
+			if err != nil {
+				return nil, err
+			}
+			slices.Sort(alts)
+			if i == 0 {
+				first = alts
+			} else if !slices.Equal(first, alts) {
+				return nil, &ParseError{Value: "alternative patterns bind different names"}
+			}
+		}
+		names = append(names, first...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			return nil, &ParseError{Value: fmt.Sprintf("multiple assignments to name '%s' in pattern", name)}
+		}
+		seen[name] = true
+	}
+	return names, nil
+}
+
+func (p *Parser) parseTargets() (ast.Node, error) {
+	defer p.untrace(p.trace("targets"))
+	res, err := p.parseTarget()
+	if err != nil {
+		return res, err
+	}
+
+	if p.curTokenIs(token.COMMA) {
+		tuple := &ast.TupleNode{Elements: []ast.Node{res}}
+		for p.curTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.canStartExpression() {
+				break
+			}
+
+			res, err := p.parseTarget()
+			if err != nil {
+				return tuple, err
+			}
+			tuple.Elements = append(tuple.Elements, res)
+		}
+		res = tuple
+	}
+
+	return res, checkTarget(res)
+}
+
+// parseTarget parses a single, possibly starred, assignment target. Targets are
+// primaries, so only attribute, call and subscript trailers may follow the atom;
+// this is what keeps the 'in' of a for statement out of the target.
+func (p *Parser) parseTarget() (ast.Node, error) {
+	defer p.untrace(p.trace("target"))
+	if p.curTokenIs(token.STAR) {
+		p.nextToken()
+		n := &ast.StarredNode{}
+		res, err := p.parseTarget()
+		n.Value = res
+		return n, err
+	}
+
+	return p.parseExpression(EXP)
+}
+
+// parseAssignmentStatement parses the rest of an assignment whose first
+// target has been parsed; the current token is '=' or an augmented operator.
+func (p *Parser) parseAssignmentStatement(res ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("assignmentStatement"))
+	stmt := &ast.AssignmentNode{Operator: p.curToken.Type}
+
+	if stmt.Operator != token.ASSIGN {
+		stmt.Targets = []ast.Node{res}
+		if err := checkAugmentedTarget(res); err != nil {
+			return stmt, err
+		}
+
+		p.nextToken()
+		res, err := p.parseStarExpressions()
+		stmt.Value = res
+		return stmt, err
+	}
+
+	for p.curTokenIs(token.ASSIGN) || isAugmentedAssign(p.curToken.Type) {
+		if !p.curTokenIs(token.ASSIGN) {
+			return stmt, &ParseError{Value: fmt.Sprintf("unexpected %s in chained assignment", p.curToken.Literal)}
+		}
+
+		if err := checkTarget(res); err != nil {
+			return stmt, err
+		}
+		stmt.Targets = append(stmt.Targets, res)
+		p.nextToken()
+
+		value, err := p.parseStarExpressions()
+		if err != nil {
+			return stmt, err
+		}
+		res = value
+	}
+
+	stmt.Value = res
+	return stmt, nil
+}
+
+func checkTarget(n ast.Node) error {
+	switch n := n.(type) {
+	case *ast.IdentifierNode:
+		if n.Name == "None" || n.Name == "True" || n.Name == "False" {
+			return &ParseError{Value: fmt.Sprintf("cannot assign to %s", n.Name)}
+		}
+		return nil
+	case *ast.SliceNode:
+		return nil
+	case *ast.InfixNode:
+		if n.Operator == token.DOT {
+			return nil
+		}
+	case *ast.TupleNode:
+		return checkTargetList(n.Elements)
+	case *ast.ListNode:
+		return checkTargetList(n.Elements)
+	case *ast.StarredNode:
+		return &ParseError{Value: "starred assignment target must be in a list or tuple"}
+	}
+
+	return &ParseError{Value: fmt.Sprintf("cannot assign to %s", describeNode(n))}
+}
+
+func checkTargetList(targets []ast.Node) error {
+	starred := false
+	for _, target := range targets {
+		if s, ok := target.(*ast.StarredNode); ok {
+			if starred {
+				return &ParseError{Value: "multiple starred expressions in assignment"}
+			}
+			starred = true
+			target = s.Value
+		}
+
+		if err := checkTarget(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDelTarget(n ast.Node) error {
+	switch n := n.(type) {
+	case *ast.IdentifierNode:
+		if n.Name == "None" || n.Name == "True" || n.Name == "False" {
+			return &ParseError{Value: fmt.Sprintf("cannot delete %s", n.Name)}
+		}
+		return nil
+	case *ast.SliceNode:
+		return nil
+	case *ast.InfixNode:
+		if n.Operator == token.DOT {
+			return nil
+		}
+	case *ast.TupleNode:
+		for _, target := range n.Elements {
+			if err := checkDelTarget(target); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.ListNode:
+		for _, target := range n.Elements {
+			if err := checkDelTarget(target); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &ParseError{Value: fmt.Sprintf("cannot delete %s", describeNode(n))}
+}
+
+func checkAugmentedTarget(n ast.Node) error {
+	switch n := n.(type) {
+	case *ast.IdentifierNode, *ast.SliceNode:
+		return checkTarget(n)
+	case *ast.InfixNode:
+		if n.Operator == token.DOT {
+			return nil
+		}
+	}
+
+	return &ParseError{Value: fmt.Sprintf("'%s' is an illegal expression for augmented assignment", describeNode(n))}
+}
+
+func describeNode(n ast.Node) string {
+	switch n.(type) {
+	case *ast.NumberNode:
+		return "literal"
+	case *ast.CallNode:
+		return "function call"
+	case *ast.TupleNode:
+		return "tuple"
+	case *ast.ListNode:
+		return "list"
+	case *ast.StarredNode:
+		return "starred"
+	default:
+		return "expression"
+	}
+}
+
+func (p *Parser) parseStarExpressions() (ast.Node, error) {
+	defer p.untrace(p.trace("starExpressions"))
+	res, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return res, err
+	}
+
+	return p.parseTupleRest(res)
+}
+
+// parseTupleRest continues a comma separated tuple after its first element.
+func (p *Parser) parseTupleRest(first ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("tupleRest"))
+	if !p.curTokenIs(token.COMMA) {
+		return first, nil
+	}
+
+	tuple := &ast.TupleNode{Elements: []ast.Node{first}}
+	for p.curTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.canStartExpression() {
+			break
+		}
+
+		res, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return tuple, err
+		}
+		tuple.Elements = append(tuple.Elements, res)
+	}
+
+	return tuple, nil
+}
+
+func (p *Parser) parseExpression(precedence int) (ast.Node, error) {
+	defer p.untrace(p.trace("expression"))
+
+	prefix := p.prefixFns[p.curToken.Type]
+	if prefix == nil {
+		return nil, &ParseError{Value: fmt.Sprintf("no prefix parse function for %s", p.curToken.Type.String())}
+	}
+
+	leftExpr, err := prefix()
+	if err != nil {
+		return leftExpr, err
+	}
+
+	for precedence < p.curPrecedence() {
+		infix := p.infixFns[p.curToken.Type]
+		if infix == nil {
+			return leftExpr, nil
+		}
+		res, err := infix(leftExpr)
+		leftExpr = res
+		if err != nil {
+			return leftExpr, err
+		}
+	}
+
+	return leftExpr, nil
+}
+
+// parseNamedExpression parses an expression that may be an assignment
+// expression. These are only allowed unparenthesized in conditions, displays,
+// subscripts and call arguments.
+func (p *Parser) parseNamedExpression() (ast.Node, error) {
+	defer p.untrace(p.trace("namedExpression"))
+	if !p.curTokenIs(token.IDENTIFIER) || p.peekToken.Type != token.WALRUS {
+		return p.parseExpression(LOWEST)
+	}
+
+	n := &ast.NamedExprNode{}
+	res, err := p.parseIdentifierPrefix()
+	n.Target = res
+	if err != nil {
+		return n, err
+	}
+
+	name := res.(*ast.IdentifierNode).Name
+	if name == "None" || name == "True" || name == "False" {
+		return n, &ParseError{Value: fmt.Sprintf("cannot use assignment expressions with %s", name)}
+	}
+	p.namedExprs = append(p.namedExprs, name)
+
+	if err := p.expect(token.WALRUS); err != nil {
+		return n, err
+	}
+
+	res, err = p.parseExpression(LOWEST)
+	n.Value = res
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseIdentifierPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("identifierPrefix"))
+	if !p.curTokenIs(token.IDENTIFIER) {
+		return nil, p.curError(token.IDENTIFIER)
+	}
+
+	defer p.nextToken()
+	return &ast.IdentifierNode{Name: p.curToken.Literal}, nil
+}
+
+func (p *Parser) parseNumberPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("numberPrefix"))
+	if !p.curTokenIs(token.NUMBER) {
+		return nil, p.curError(token.NUMBER)
+	}
+
+	defer p.nextToken()
+	return &ast.NumberNode{Value: p.curToken.Literal}, nil
+}
+
+// parseStringPrefix joins adjacent string literals, as in "a" 'b'.
+func (p *Parser) parseStringPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("stringPrefix"))
+	if !p.curTokenIs(token.STRING) {
+		return nil, p.curError(token.STRING)
+	}
+
+	var value strings.Builder
+	for p.curTokenIs(token.STRING) {
+		s, err := unquote(p.curToken.Literal)
+		if err != nil {
+			return nil, err
+		}
+		value.WriteString(s)
+		p.nextToken()
+	}
+
+	return &ast.StringNode{Value: value.String()}, nil
+}
+
+func unquote(literal string) (string, error) {
+	body := literal[1 : len(literal)-1]
+	if !strings.ContainsRune(body, '\\') {
+		return body, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			out.WriteByte(body[i])
+			continue
+		}
+
+		i++
+		switch c := body[i]; c {
+		case '\n':
+		case '\\', '\'', '"':
+			out.WriteByte(c)
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case '0':
+			out.WriteByte(0)
+		case 'a':
+			out.WriteByte('\a')
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'v':
+			out.WriteByte('\v')
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			if i+size >= len(body) {
+				return "", &ParseError{Value: fmt.Sprintf("truncated \\%c escape in %s", c, literal)}
+			}
+			r, err := strconv.ParseUint(body[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", &ParseError{Value: fmt.Sprintf("invalid \\%c escape in %s", c, literal)}
+			}
+			out.WriteRune(rune(r))
+			i += size
+		default:
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String(), nil
+}
+
+func (p *Parser) parseExpressionPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("expressionPrefix"))
+	expression := &ast.PrefixNode{
+		Operator: p.curToken.Type,
+	}
+	p.nextToken()
+
+	res, err := p.parseExpression(PREFIX)
+	expression.Right = res
+	if err != nil {
+		return expression, err
+	}
+
+	return expression, nil
+}
+
+func (p *Parser) parseNotPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("notPrefix"))
+	expression := &ast.PrefixNode{
+		Operator: p.curToken.Type,
+	}
+	p.nextToken()
+
+	res, err := p.parseExpression(NOT)
+	expression.Right = res
+	if err != nil {
+		return expression, err
+	}
+
+	return expression, nil
+}
+
+// parseYieldPrefix parses a yield expression and marks the enclosing function
+// as a generator.
+func (p *Parser) parseYieldPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("yieldPrefix"))
+	if err := p.expect(token.YIELD); err != nil {
+		return nil, err
+	}
+
+	if !p.inFunction() {
+		return nil, &ParseError{Value: "'yield' outside function"}
+	}
+	p.functions[len(p.functions)-1].isGenerator = true
+	p.yields++
+
+	n := &ast.YieldNode{}
+	if p.curTokenIs(token.FROM) {
+		p.nextToken()
+		n.From = true
+		res, err := p.parseExpression(LOWEST)
+		n.Value = res
+		return n, err
+	}
+
+	if p.canStartExpression() {
+		res, err := p.parseStarExpressions()
+		n.Value = res
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseLambdaPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("lambdaPrefix"))
+	if err := p.expect(token.LAMBDA); err != nil {
+		return nil, err
+	}
+
+	n := &ast.LambdaNode{}
+	params, err := p.parseParams(token.COLON)
+	n.Params = params
+	if err != nil {
+		return n, err
+	}
+
+	if err := p.expect(token.COLON); err != nil {
+		return n, err
+	}
+
+	scope := p.enterFunction()
+	res, err := p.parseExpression(LOWEST)
+	p.leaveFunction()
+	n.Body = res
+	n.IsGenerator = scope.isGenerator
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseGroupPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("groupPrefix"))
+	if !p.curTokenIs(token.LPAREN) {
+		return nil, p.curError(token.LPAREN)
+	}
+
+	p.nextToken()
+	if p.curTokenIs(token.RPAREN) {
+		p.nextToken()
+		return &ast.TupleNode{Elements: []ast.Node{}}, nil
+	}
+
+	mark := p.markComprehension()
+	res, err := p.parseNamedExpression()
+	if err != nil {
+		return res, err
+	}
+
+	if p.curTokenIs(token.FOR) {
+		n := &ast.GeneratorExpNode{Element: res}
+		clauses, err := p.parseComprehensionClauses(mark, "generator expression")
+		n.Clauses = clauses
+		if err != nil {
+			return n, err
+		}
+		res = n
+	} else if res, err = p.parseTupleRest(res); err != nil {
+		return res, err
 	}
 
-	if err := p.expect(token.COLON); err != nil {
-		return nil, err
-	}
+	if err := p.expect(token.RPAREN); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (p *Parser) parseListPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("listPrefix"))
+	n := &ast.ListNode{Elements: []ast.Node{}}
+
+	if err := p.expect(token.LBRACKET); err != nil {
+		return n, err
+	}
+
+	for !p.curTokenIs(token.RBRACKET) {
+		mark := p.markComprehension()
+		res, err := p.parseNamedExpression()
+		if err != nil {
+			return n, err
+		}
+
+		if len(n.Elements) == 0 && p.curTokenIs(token.FOR) {
+			comp := &ast.ListCompNode{Element: res}
+			clauses, err := p.parseComprehensionClauses(mark, "list comprehension")
+			comp.Clauses = clauses
+			if err != nil {
+				return comp, err
+			}
+
+			if err := p.expect(token.RBRACKET); err != nil {
+				return comp, err
+			}
+			return comp, nil
+		}
 
-	return p.parseBlock()
-}
+		n.Elements = append(n.Elements, res)
 
-func (p *Parser) parseForStatement() (ast.Node, error) {
-	defer untrace(trace("forStatement"))
-	if err := p.expect(token.FOR); err != nil {
-		return nil, err
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
+		}
 	}
 
-	stmt := &ast.ForNode{}
-	res, err := p.parseTargets()
-	stmt.Targets = res
-	if err != nil {
-		return stmt, err
+	if err := p.expect(token.RBRACKET); err != nil {
+		return n, err
 	}
 
-	if err := p.expect(token.IN); err != nil {
-		return nil, err
-	}
+	return n, nil
+}
 
-	res, err = p.parseExpression(LOWEST)
-	stmt.Values = res
-	if err != nil {
-		return stmt, err
-	}
+// parseDictPrefix parses the brace displays: dicts, sets and their
+// comprehensions. The first element decides which one it is.
+func (p *Parser) parseDictPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("dictPrefix"))
+	n := &ast.DictNode{Keys: []ast.Node{}, Values: []ast.Node{}}
 
-	if err := p.expect(token.COLON); err != nil {
-		return nil, err
+	if err := p.expect(token.LBRACE); err != nil {
+		return n, err
 	}
 
-	res, err = p.parseBlock()
-	stmt.Body = res
-	if err != nil {
-		return stmt, err
-	}
+	for !p.curTokenIs(token.RBRACE) {
+		mark := p.markComprehension()
+		key, err := p.parseNamedExpression()
+		if err != nil {
+			return n, err
+		}
 
-	if p.curTokenIs(token.ELSE) {
-		res, err := p.parseElseBlock()
-		stmt.Else = res
+		if len(n.Keys) == 0 && !p.curTokenIs(token.COLON) {
+			return p.parseSetDisplay(key, mark)
+		}
+		if _, ok := key.(*ast.NamedExprNode); ok {
+			return n, &ParseError{Value: "assignment expression as a dict key must be parenthesized"}
+		}
+
+		if err := p.expect(token.COLON); err != nil {
+			return n, err
+		}
+
+		value, err := p.parseExpression(LOWEST)
 		if err != nil {
-			return stmt, err
+			return n, err
+		}
+
+		if len(n.Keys) == 0 && p.curTokenIs(token.FOR) {
+			comp := &ast.DictCompNode{Key: key, Value: value}
+			clauses, err := p.parseComprehensionClauses(mark, "dict comprehension")
+			comp.Clauses = clauses
+			if err != nil {
+				return comp, err
+			}
+
+			if err := p.expect(token.RBRACE); err != nil {
+				return comp, err
+			}
+			return comp, nil
+		}
+
+		n.Keys = append(n.Keys, key)
+		n.Values = append(n.Values, value)
+
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else {
+			break
 		}
 	}
 
-	return stmt, nil
-}
+	if err := p.expect(token.RBRACE); err != nil {
+		return n, err
+	}
 
-func (p *Parser) parseTargets() (ast.Node, error) {
-	defer untrace(trace("targets"))
-	return p.parseExpression(LOWEST) // TODO: implement real version
+	return n, nil
 }
 
-func (p *Parser) parseAssignmentStatement() (ast.Node, error) {
-	defer untrace(trace("assignmentStatement"))
-	stmt, err := p.parseExpression(LOWEST)
-	if err != nil {
-		return stmt, err
-	}
+// parseSetDisplay continues a set display or comprehension after its first
+// element, which started at mark.
+func (p *Parser) parseSetDisplay(first ast.Node, mark comprehensionMark) (ast.Node, error) {
+	defer p.untrace(p.trace("setDisplay"))
+	if p.curTokenIs(token.FOR) {
+		comp := &ast.SetCompNode{Element: first}
+		clauses, err := p.parseComprehensionClauses(mark, "set comprehension")
+		comp.Clauses = clauses
+		if err != nil {
+			return comp, err
+		}
 
-	if !p.curTokenIs(token.ASSIGN) {
-		return stmt, p.curError(token.ASSIGN)
+		if err := p.expect(token.RBRACE); err != nil {
+			return comp, err
+		}
+		return comp, nil
 	}
 
-	assignment := &ast.AssignmentNode{Target: stmt, Operator: p.curToken.Literal}
-	p.nextToken()
+	n := &ast.SetNode{Elements: []ast.Node{first}}
+	for p.curTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.curTokenIs(token.RBRACE) {
+			break
+		}
 
-	res, err := p.parseExpression(LOWEST)
-	assignment.Value = res
-	if err != nil {
-		return stmt, err
+		res, err := p.parseNamedExpression()
+		if err != nil {
+			return n, err
+		}
+		n.Elements = append(n.Elements, res)
 	}
 
-	stmt = assignment
+	if err := p.expect(token.RBRACE); err != nil {
+		return n, err
+	}
 
-	return stmt, nil
+	return n, nil
 }
 
-func (p *Parser) parseExpression(precedence int) (ast.Node, error) {
-	defer untrace(trace("expression"))
+// parseComprehensionClauses parses the 'for' and 'if' clauses that follow the
+// element of a comprehension, which started at mark. kind names the
+// comprehension in errors.
+func (p *Parser) parseComprehensionClauses(mark comprehensionMark, kind string) ([]*ast.ComprehensionNode, error) {
+	defer p.untrace(p.trace("comprehensionClauses"))
+	clauses := []*ast.ComprehensionNode{}
 
-	prefix := p.prefixFns[p.curToken.Type]
-	if prefix == nil {
-		return nil, &ParseError{Value: fmt.Sprintf("no prefix parse function for %s", p.curToken.Type.String())}
-	}
+	for p.curTokenIs(token.FOR) {
+		p.nextToken()
+		clause := &ast.ComprehensionNode{}
+		clauses = append(clauses, clause)
 
-	leftExpr, err := prefix()
-	if err != nil {
-		return leftExpr, err
-	}
+		res, err := p.parseTargets()
+		clause.Targets = res
+		if err != nil {
+			return clauses, err
+		}
 
-	for precedence < getPrecedence(p.curToken.Type) {
-		infix := p.infixFns[p.curToken.Type]
-		if infix == nil {
-			return leftExpr, nil
+		if err := p.expect(token.IN); err != nil {
+			return clauses, err
 		}
-		res, err := infix(leftExpr)
-		leftExpr = res
+
+		named, yields := len(p.namedExprs), p.yields
+		res, err = p.parseExpression(CONDITIONAL)
+		clause.Values = res
 		if err != nil {
-			return leftExpr, err
+			return clauses, err
+		}
+		if len(p.namedExprs) > named {
+			return clauses, &ParseError{Value: "assignment expression cannot be used in a comprehension iterable expression"}
+		}
+		// The first iterable is evaluated in the enclosing scope, where it may yield.
+		if len(clauses) == 1 {
+			mark.yields += p.yields - yields
+		}
+
+		for p.curTokenIs(token.IF) {
+			p.nextToken()
+			res, err := p.parseExpression(CONDITIONAL)
+			if err != nil {
+				return clauses, err
+			}
+			clause.Ifs = append(clause.Ifs, res)
 		}
 	}
 
-	return leftExpr, nil
+	if p.yields > mark.yields {
+		return clauses, &ParseError{Value: fmt.Sprintf("'yield' inside %s", kind)}
+	}
+	return clauses, p.checkComprehension(clauses, mark)
 }
 
-func (p *Parser) parseIdentifierPrefix() (ast.Node, error) {
-	defer untrace(trace("identifierPrefix"))
-	if !p.curTokenIs(token.IDENTIFIER) {
-		return nil, p.curError(token.IDENTIFIER)
+// checkComprehension applies PEP 572 to the assignment expressions in a
+// comprehension: they bind in the enclosing scope, which may not be a class
+// body, and may not rebind an iteration variable.
+func (p *Parser) checkComprehension(clauses []*ast.ComprehensionNode, mark comprehensionMark) error {
+	named := p.namedExprs[mark.namedExprs:]
+	if len(named) == 0 {
+		return nil
+	}
+	if p.inClass() {
+		return &ParseError{Value: "assignment expression within a comprehension cannot be used in a class body"}
 	}
 
-	defer p.nextToken()
-	return &ast.IdentifierNode{Name: p.curToken.Literal}, nil
+	for _, clause := range clauses {
+		for _, name := range targetNames(clause.Targets) {
+			if slices.Contains(named, name) {
+				return &ParseError{Value: fmt.Sprintf("assignment expression cannot rebind comprehension iteration variable '%s'", name)}
+			}
+		}
+	}
+	return nil
 }
 
-func (p *Parser) parseNumberPrefix() (ast.Node, error) {
-	defer untrace(trace("numberPrefix"))
-	if !p.curTokenIs(token.NUMBER) {
-		return nil, p.curError(token.NUMBER)
+// targetNames returns the names bound by an assignment target.
+func targetNames(target ast.Node) []string {
+	switch t := target.(type) {
+	case *ast.IdentifierNode:
+		return []string{t.Name}
+	case *ast.StarredNode:
+		return targetNames(t.Value)
+	case *ast.TupleNode:
+		return elementNames(t.Elements)
+	case *ast.ListNode:
+		return elementNames(t.Elements)
+	}
+	return nil
+}
+
+func elementNames(elements []ast.Node) []string {
+	names := []string{}
+	for _, el := range elements {
+		names = append(names, targetNames(el)...)
+	}
+	return names
+}
+
+func (p *Parser) parseStarredPrefix() (ast.Node, error) {
+	defer p.untrace(p.trace("starredPrefix"))
+	if p.curToken.Literal != "*" {
+		return nil, &ParseError{Value: fmt.Sprintf("unexpected operator %s", p.curToken.Literal)}
 	}
 
-	defer p.nextToken()
-	return &ast.NumberNode{Value: p.curToken.Literal}, nil
+	p.nextToken()
+	n := &ast.StarredNode{}
+	res, err := p.parseExpression(COMPARE)
+	n.Value = res
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
 }
 
-func (p *Parser) parseExpressionPrefix() (ast.Node, error) {
-	defer untrace(trace("expressionPrefix"))
-	expression := &ast.PrefixNode{
-		Operator: p.curToken.Literal,
+func (p *Parser) parseExpressionInfix(left ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("expressionInfix"))
+	expression := &ast.InfixNode{
+		Operator: p.curToken.Type,
+		Left:     left,
+	}
+	precedence := getPrecedence(p.curToken.Type)
+	if p.curTokenIs(token.DSTAR) {
+		precedence -= 1
 	}
+
 	p.nextToken()
 
-	res, err := p.parseExpression(PREFIX)
-	expression.Right = res
+	res, err := p.parseExpression(precedence)
 	if err != nil {
 		return expression, err
 	}
 
+	expression.Right = res
 	return expression, nil
 }
 
-func (p *Parser) parseGroupPrefix() (ast.Node, error) {
-	defer untrace(trace("groupPrefix"))
-	if !p.curTokenIs(token.LPAREN) {
-		return nil, p.curError(token.LPAREN)
+// parseNotInInfix parses the 'not in' comparison, which is lexed as the
+// two keywords.
+func (p *Parser) parseNotInInfix(left ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("notInInfix"))
+	expression := &ast.InfixNode{
+		Operator: token.NOT_IN,
+		Left:     left,
 	}
-
 	p.nextToken()
-	res, err := p.parseExpression(LOWEST)
-	if err != nil {
-		return res, err
+
+	if err := p.expect(token.IN); err != nil {
+		return expression, err
 	}
 
-	if err := p.expect(token.RPAREN); err != nil {
-		return res, err
+	res, err := p.parseExpression(COMPARE)
+	if err != nil {
+		return expression, err
 	}
 
-	return res, nil
+	expression.Right = res
+	return expression, nil
 }
 
-func (p *Parser) parseExpressionInfix(left ast.Node) (ast.Node, error) {
-	defer untrace(trace("expressionInfix"))
-	expression := &ast.InfixNode{
-		Operator: p.curToken.Literal,
-		Left:     left,
+// parseConditionalInfix parses 'body if condition else orelse'. The else
+// branch is parsed at the lowest precedence so chains associate to the right.
+func (p *Parser) parseConditionalInfix(left ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("conditionalInfix"))
+	expression := &ast.ConditionalNode{Body: left}
+
+	if err := p.expect(token.IF); err != nil {
+		return expression, err
 	}
-	precedence := getPrecedence(p.curToken.Type)
-	if p.curTokenIs(token.EXP) {
-		precedence -= 1
+
+	res, err := p.parseExpression(CONDITIONAL)
+	expression.Condition = res
+	if err != nil {
+		return expression, err
 	}
 
-	p.nextToken()
+	if err := p.expect(token.ELSE); err != nil {
+		return expression, err
+	}
 
-	res, err := p.parseExpression(precedence)
+	res, err = p.parseExpression(LOWEST)
+	expression.Else = res
 	if err != nil {
 		return expression, err
 	}
 
-	expression.Right = res
 	return expression, nil
 }
 
 func (p *Parser) parseCallInfix(left ast.Node) (ast.Node, error) {
-	defer untrace(trace("callInfix"))
+	defer p.untrace(p.trace("callInfix"))
 	expression := &ast.CallNode{
 		Function: left,
 	}
@@ -650,16 +2440,59 @@ func (p *Parser) parseCallInfix(left ast.Node) (ast.Node, error) {
 }
 
 func (p *Parser) parseArgs() ([]ast.Node, error) {
-	defer untrace(trace("args"))
+	defer p.untrace(p.trace("args"))
 	args := []ast.Node{}
 
+	keywords := false
+	unpacking := false
+
 	for !p.curTokenIs(token.RPAREN) {
-		res, err := p.parseExpression(LOWEST)
-		if err != nil {
-			return args, err
-		}
+		if p.curTokenIs(token.DSTAR) {
+			p.nextToken()
+			res, err := p.parseExpression(LOWEST)
+			args = append(args, &ast.DoubleStarredNode{Value: res})
+			if err != nil {
+				return args, err
+			}
+			unpacking = true
+		} else if p.curTokenIs(token.IDENTIFIER) && p.peekToken.Type == token.ASSIGN {
+			res, err := p.parseKeywordArg()
+			args = append(args, res)
+			if err != nil {
+				return args, err
+			}
+			keywords = true
+		} else {
+			mark := p.markComprehension()
+			res, err := p.parseNamedExpression()
+			if err != nil {
+				return args, err
+			}
 
-		args = append(args, res)
+			if p.curTokenIs(token.FOR) {
+				n := &ast.GeneratorExpNode{Element: res}
+				clauses, err := p.parseComprehensionClauses(mark, "generator expression")
+				n.Clauses = clauses
+				if err != nil {
+					return args, err
+				}
+				if len(args) > 0 || !p.curTokenIs(token.RPAREN) {
+					return args, &ParseError{Value: "Generator expression must be parenthesized"}
+				}
+				res = n
+			}
+
+			if _, ok := res.(*ast.StarredNode); ok {
+				if unpacking {
+					return args, &ParseError{Value: "iterable argument unpacking follows keyword argument unpacking"}
+				}
+			} else if unpacking {
+				return args, &ParseError{Value: "positional argument follows keyword argument unpacking"}
+			} else if keywords {
+				return args, &ParseError{Value: "positional argument follows keyword argument"}
+			}
+			args = append(args, res)
+		}
 
 		if p.curTokenIs(token.COMMA) {
 			p.nextToken()
@@ -671,13 +2504,35 @@ func (p *Parser) parseArgs() ([]ast.Node, error) {
 	return args, nil
 }
 
-func (p *Parser) parseSlicesInfix(left ast.Node) (ast.Node, error) { // TODO: support [a:b:c]
-	defer untrace(trace("slicesInfix"))
+func (p *Parser) parseKeywordArg() (ast.Node, error) {
+	defer p.untrace(p.trace("keywordArg"))
+	n := &ast.KeywordNode{}
+	res, err := p.parseIdentifierPrefix()
+	n.Name = res
+	if err != nil {
+		return n, err
+	}
+
+	if err := p.expect(token.ASSIGN); err != nil {
+		return n, err
+	}
+
+	res, err = p.parseExpression(LOWEST)
+	n.Value = res
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (p *Parser) parseSlicesInfix(left ast.Node) (ast.Node, error) {
+	defer p.untrace(p.trace("slicesInfix"))
 	n := &ast.SliceNode{Left: left}
 
 	p.nextToken()
 
-	res, err := p.parseExpression(LOWEST)
+	res, err := p.parseSlice()
 	n.Index = res
 	if err != nil {
 		return n, err
@@ -690,6 +2545,43 @@ func (p *Parser) parseSlicesInfix(left ast.Node) (ast.Node, error) { // TODO: su
 	return n, nil
 }
 
+// parseSlice parses a subscript index, which is either an expression or
+// 'lower:upper:step' with every part optional.
+func (p *Parser) parseSlice() (ast.Node, error) {
+	defer p.untrace(p.trace("slice"))
+	var lower ast.Node
+	if !p.curTokenIs(token.COLON) {
+		res, err := p.parseNamedExpression()
+		if err != nil || !p.curTokenIs(token.COLON) {
+			return res, err
+		}
+		lower = res
+	}
+	p.nextToken()
+
+	n := &ast.SliceExprNode{Lower: lower}
+	if !p.curTokenIs(token.COLON) && !p.curTokenIs(token.RBRACKET) {
+		res, err := p.parseExpression(LOWEST)
+		n.Upper = res
+		if err != nil {
+			return n, err
+		}
+	}
+
+	if p.curTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.curTokenIs(token.RBRACKET) {
+			res, err := p.parseExpression(LOWEST)
+			n.Step = res
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 
@@ -711,6 +2603,12 @@ func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
 
+// curTokenIsSoftKeyword reports whether the current token is the identifier
+// kw, such as match or case, which are keywords only in some positions.
+func (p *Parser) curTokenIsSoftKeyword(kw string) bool {
+	return p.curTokenIs(token.IDENTIFIER) && p.curToken.Literal == kw
+}
+
 func (p *Parser) expect(t token.TokenType) error {
 	if p.curTokenIs(t) {
 		p.nextToken()
@@ -724,11 +2622,27 @@ func (p *Parser) curError(t token.TokenType) error {
 	return &ParseError{Value: fmt.Sprintf("expected token to be %s, got %s instead", t, p.curToken.Type)}
 }
 
+func isAugmentedAssign(tok token.TokenType) bool {
+	_, ok := token.AugmentedOp(tok)
+	return ok
+}
+
 func getPrecedence(tok token.TokenType) int {
-	if p, ok := precedences[tok]; ok {
-		return p
+	return token.Precedence(tok)
+}
+
+// curPrecedence is the precedence of the current token as an infix operator.
+// 'not' is only one as the start of 'not in'.
+func (p *Parser) curPrecedence() int {
+	if p.curTokenIs(token.NOT) && p.peekToken.Type == token.IN {
+		return COMPARE
 	}
-	return LOWEST
+	return getPrecedence(p.curToken.Type)
+}
+
+func (p *Parser) canStartExpression() bool {
+	_, ok := p.prefixFns[p.curToken.Type]
+	return ok
 }
 
 func (p *Parser) isCompoundStatement() bool {
@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"fmt"
+	"snek/lexer"
+	"strings"
+	"testing"
+)
+
+func parseFile(t *testing.T, input string) error {
+	t.Helper()
+	l := lexer.New(input)
+	tokens := l.Tokenize()
+	if errs := l.ErrorList(); len(errs) > 0 {
+		t.Fatalf("lexing %q: %v", input, errs[0])
+	}
+	_, err := New(tokens).ParseFile()
+	return err
+}
+
+type syntaxTest struct {
+	input    string
+	expected string // The error, or "" if the input is valid
+}
+
+func testSyntax(t *testing.T, tests []syntaxTest) {
+	t.Helper()
+	for _, tt := range tests {
+		err := parseFile(t, tt.input)
+		switch {
+		case tt.expected == "" && err != nil:
+			t.Errorf("parsing %q failed: %v", tt.input, err)
+		case tt.expected != "" && err == nil:
+			t.Errorf("parsing %q succeeded, want %q", tt.input, tt.expected)
+		case tt.expected != "" && err.Error() != tt.expected:
+			t.Errorf("parsing %q gave %q, want %q", tt.input, err, tt.expected)
+		}
+	}
+}
+
+func TestNamedExpressionsInComprehensions(t *testing.T) {
+	testSyntax(t, []syntaxTest{
+		{"[(x := y) for y in range(2)]\n", ""},
+		{"[(y := 1) for y in range(2)]\n", "assignment expression cannot rebind comprehension iteration variable 'y'"},
+		{"{(b := 1) for a, *b in x}\n", "assignment expression cannot rebind comprehension iteration variable 'b'"},
+		{"[[(a := 1) for b in c] for a in d]\n", "assignment expression cannot rebind comprehension iteration variable 'a'"},
+		{"f((x := 1) for y in z if (y := 2))\n", "assignment expression cannot rebind comprehension iteration variable 'y'"},
+		{"[y for y in (x := [1])]\n", "assignment expression cannot be used in a comprehension iterable expression"},
+		{"class A:\n    z = [(x := y) for y in range(2)]\n", "assignment expression within a comprehension cannot be used in a class body"},
+		{"class A:\n    def f(self):\n        return [(x := y) for y in range(2)]\n", ""},
+		{"class A:\n    z = [lambda: (x := y) for y in range(2)]\n", ""},
+		{"class A:\n    z = (x := 1)\n", ""},
+	})
+}
+
+func TestYieldInComprehensions(t *testing.T) {
+	testSyntax(t, []syntaxTest{
+		{"def f():\n    return [(yield x) for x in y]\n", "'yield' inside list comprehension"},
+		{"def f():\n    return {(yield x) for x in y}\n", "'yield' inside set comprehension"},
+		{"def f():\n    return {x: (yield) for x in y}\n", "'yield' inside dict comprehension"},
+		{"def f():\n    return ((yield x) for x in y)\n", "'yield' inside generator expression"},
+		{"def f():\n    return g((yield x) for x in y)\n", "'yield' inside generator expression"},
+		{"def f():\n    return [x for x in y if (yield x)]\n", "'yield' inside list comprehension"},
+		{"def f():\n    return [x for x in y for z in (yield x)]\n", "'yield' inside list comprehension"},
+		{"def f():\n    return [x for x in (yield y)]\n", ""},
+		{"def f():\n    return [lambda: (yield x) for x in y]\n", ""},
+		{"def f():\n    return [(yield x), 1]\n", ""},
+	})
+}
+
+func TestControlStatementScopes(t *testing.T) {
+	testSyntax(t, []syntaxTest{
+		{"break\n", "'break' outside loop"},
+		{"continue\n", "'continue' not properly in loop"},
+		{"return 1\n", "'return' outside function"},
+		{"if x:\n    break\n", "'break' outside loop"},
+		{"while x:\n    if y:\n        break\n    continue\n", ""},
+		{"for x in y:\n    try:\n        continue\n    finally:\n        break\n", ""},
+		{"for x in y:\n    with z:\n        break\n", ""},
+		{"for x in y:\n    def f():\n        break\n", "'break' outside loop"},
+		{"while x:\n    def f():\n        continue\n", "'continue' not properly in loop"},
+		{"for x in y:\n    class A:\n        break\n", "'break' outside loop"},
+		{"def f():\n    for x in y:\n        def g():\n            return x\n        break\n", ""},
+		{"for x in y:\n    pass\nelse:\n    break\n", "'break' outside loop"},
+		{"while x:\n    pass\nelse:\n    continue\n", "'continue' not properly in loop"},
+		{"for x in y:\n    for z in x:\n        pass\n    else:\n        break\n", ""},
+		{"for x in y:\n    pass\nbreak\n", "'break' outside loop"},
+		{"class A:\n    return 1\n", "'return' outside function"},
+		{"class A:\n    def f(self):\n        return 1\n", ""},
+		{"def f():\n    class A:\n        return 1\n", "'return' outside function"},
+		{"f = lambda: 1\n", ""},
+	})
+}
+
+func nested(open, inner, close string, depth int) string {
+	return strings.Repeat(open, depth) + inner + strings.Repeat(close, depth)
+}
+
+// benchmarkParse parses the input made by src at growing depths, which should
+// take time linear in the depth.
+func benchmarkParse(b *testing.B, src func(depth int) string) {
+	for _, depth := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			l := lexer.New(src(depth) + "\n")
+			tokens := l.Tokenize()
+			if errs := l.ErrorList(); len(errs) > 0 {
+				b.Fatal(errs[0])
+			}
+			for b.Loop() {
+				if _, err := New(tokens).ParseFile(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseParens(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("(", "1", ")", depth) })
+}
+
+func BenchmarkParseLists(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("[1, ", "1", "]", depth) })
+}
+
+func BenchmarkParseCalls(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("f(", "1", ")", depth) })
+}
+
+func BenchmarkParseLambdas(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("lambda: ", "1", "", depth) })
+}
+
+func BenchmarkParseComprehensions(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("[", "x", " for x in y]", depth) })
+}
+
+func BenchmarkParseAssignments(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("a = ", "1", "", depth) })
+}
+
+func BenchmarkParseAssignmentTargets(b *testing.B) {
+	benchmarkParse(b, func(depth int) string { return nested("[a, ", "b", "]", depth) + " = x" })
+}
+
+func TestNotPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"not a in b", "(not (a in b))"},
+		{"a not in b", "(a not in b)"},
+		{"not a not in b", "(not (a not in b))"},
+		{"a not in b and c", "((a not in b) and c)"},
+		{"not a == b or c", "((not (a == b)) or c)"},
+		{"not not a", "(not (not a))"},
+	}
+	for _, tt := range tests {
+		res, err := ParseExpression(tt.input)
+		if err != nil || res.String() != tt.expected {
+			t.Errorf("ParseExpression(%q) = %v, %v, want %s", tt.input, res, err, tt.expected)
+		}
+	}
+	if res, err := ParseExpression("a not b"); err == nil {
+		t.Errorf("ParseExpression(%q) = %v, want an error", "a not b", res)
+	}
+}
+
+func TestMatchSyntax(t *testing.T) {
+	testSyntax(t, []syntaxTest{
+		{"match x:\n    case 1 | 2:\n        pass\n    case _:\n        pass\n", ""},
+		{"match x:\n    case [a, *b] | (a, *b):\n        pass\n", ""},
+		{"match x:\n    case [a] | [b]:\n        pass\n", "alternative patterns bind different names"},
+		{"match x:\n    case {'k': a} | 1:\n        pass\n", "alternative patterns bind different names"},
+		{"match x:\n    case [a, a]:\n        pass\n", "multiple assignments to name 'a' in pattern"},
+		{"match x:\n    case [*a, *b]:\n        pass\n", "multiple starred names in sequence pattern"},
+		{"match x:\n    case _:\n        pass\n    case 1:\n        pass\n", "wildcard makes remaining patterns unreachable"},
+		{"match x:\n    case y:\n        pass\n    case 1:\n        pass\n", "name capture 'y' makes remaining patterns unreachable"},
+		{"match x:\n    case _ | 1:\n        pass\n", "wildcard makes remaining patterns unreachable"},
+		{"match x:\n    case (y as z):\n        pass\n    case 1:\n        pass\n", "name capture 'y' makes remaining patterns unreachable"},
+		{"match x:\n    case y if y:\n        pass\n    case 1:\n        pass\n", ""},
+		{"match x:\n    case 1 | _:\n        pass\n", ""},
+		{"match x:\n    case {**rest, 'k': 1}:\n        pass\n", "the ** pattern must come last in a mapping pattern"},
+		{"match x:\n    case {a: 1}:\n        pass\n", "mapping pattern keys may only match literals and attribute lookups"},
+		{"match x:\n    case P(x=1, x=2):\n        pass\n", "attribute name repeated in class pattern: x"},
+		{"match x:\n    case P(x=1, 2):\n        pass\n", "positional patterns follow keyword patterns"},
+		{"match x:\n    pass\n", "expected 'case' block"},
+		{"match = 1\ncase = match\nmatch(case)\n", ""},
+		{"match [1]:\n    case [_]:\n        pass\n", ""},
+	})
+}
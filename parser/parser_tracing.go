@@ -2,31 +2,52 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"snek/token"
 	"strings"
 )
 
-var traceLevel int = 0
-
 const traceIdentPlaceholder string = "  "
 
-func identLevel() string {
-	return strings.Repeat(traceIdentPlaceholder, traceLevel-1)
+// TraceEvent reports entering or leaving a parse function.
+type TraceEvent struct {
+	Name  string
+	Enter bool
+	Depth int         // Parse functions active, counting this one
+	Token token.Token // The token current when the event happened
 }
 
-func tracePrint(fs string) {
-	fmt.Printf("%s%s\n", identLevel(), fs)
+// WithTracer calls fn as each parse function is entered and left. Tracing
+// is off unless a tracer is set.
+func WithTracer(fn func(TraceEvent)) Option {
+	return func(p *Parser) { p.tracer = fn }
 }
 
-func incIdent() { traceLevel = traceLevel + 1 }
-func decIdent() { traceLevel = traceLevel - 1 }
+// WithTraceOutput writes the parse functions to w as they are entered and
+// left, indented by depth.
+func WithTraceOutput(w io.Writer) Option {
+	return WithTracer(func(e TraceEvent) {
+		sign := "-"
+		if e.Enter {
+			sign = "+"
+		}
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(traceIdentPlaceholder, e.Depth-1), sign, e.Name)
+	})
+}
 
-func trace(msg string) string {
-	incIdent()
-	tracePrint("+ " + msg)
+func (p *Parser) trace(msg string) string {
+	if p.tracer == nil {
+		return msg
+	}
+	p.traceLevel++
+	p.tracer(TraceEvent{Name: msg, Enter: true, Depth: p.traceLevel, Token: p.curToken})
 	return msg
 }
 
-func untrace(msg string) {
-	tracePrint("- " + msg)
-	decIdent()
+func (p *Parser) untrace(msg string) {
+	if p.tracer == nil {
+		return
+	}
+	p.tracer(TraceEvent{Name: msg, Depth: p.traceLevel, Token: p.curToken})
+	p.traceLevel--
 }
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"snek/lexer"
+	"strings"
+	"testing"
+)
+
+func TestTracerSilentByDefault(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, parseErr := New(lexer.New("x = [f(y) for y in z]\n").Tokenize()).ParseFile()
+	os.Stdout = stdout
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	if parseErr != nil {
+		t.Fatalf("parsing failed: %v", parseErr)
+	}
+	if len(out) > 0 {
+		t.Errorf("parsing without a tracer printed %q", out)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	input := "def f(a):\n    return [a + b for b in c if b]\n"
+	var stack []string
+	events := 0
+	tracer := func(e TraceEvent) {
+		events++
+		if e.Enter {
+			stack = append(stack, e.Name)
+			if e.Depth != len(stack) {
+				t.Errorf("entering %s at depth %d, want %d", e.Name, e.Depth, len(stack))
+			}
+			return
+		}
+		if len(stack) == 0 || stack[len(stack)-1] != e.Name {
+			t.Fatalf("leaving %s, which is not the innermost function in %v", e.Name, stack)
+		}
+		if e.Depth != len(stack) {
+			t.Errorf("leaving %s at depth %d, want %d", e.Name, e.Depth, len(stack))
+		}
+		stack = stack[:len(stack)-1]
+	}
+
+	if _, err := New(lexer.New(input).Tokenize(), WithTracer(tracer)).ParseFile(); err != nil {
+		t.Fatalf("parsing failed: %v", err)
+	}
+	if len(stack) > 0 {
+		t.Errorf("parse functions %v were never left", stack)
+	}
+	if events == 0 {
+		t.Errorf("the tracer was never called")
+	}
+}
+
+func TestTraceOutput(t *testing.T) {
+	var out strings.Builder
+	if _, err := New(lexer.New("x\n").Tokenize(), WithTraceOutput(&out)).ParseFile(); err != nil {
+		t.Fatalf("parsing failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) < 2 || lines[0] != "+ "+strings.TrimPrefix(lines[len(lines)-1], "- ") {
+		t.Fatalf("trace %q does not start and end with the same function", out.String())
+	}
+	depth := 0
+	for _, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case strings.HasPrefix(line[indent:], "+ "):
+			if indent != 2*depth {
+				t.Errorf("line %q is indented %d, want %d", line, indent, 2*depth)
+			}
+			depth++
+		case strings.HasPrefix(line[indent:], "- "):
+			depth--
+			if indent != 2*depth {
+				t.Errorf("line %q is indented %d, want %d", line, indent, 2*depth)
+			}
+		default:
+			t.Errorf("unexpected trace line %q", line)
+		}
+	}
+	if depth != 0 {
+		t.Errorf("trace ended at depth %d", depth)
+	}
+}
@@ -0,0 +1,141 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"snek/ast"
+	"snek/evaluator"
+	"snek/lexer"
+	"snek/object"
+	"snek/parser"
+)
+
+const (
+	PROMPT              = ">>> "
+	CONTINUATION_PROMPT = "... "
+)
+
+// errInterrupted is returned by a lineReader when Ctrl-C is pressed.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads a line of input after showing a prompt.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	Close() error
+}
+
+// Start runs statements read from in in a fresh __main__ module of a new
+// interpreter, made with opts and printing to out, until the input ends, echoing the repr of expression results other than None and
+// keeping the last one in _. On a terminal, lines are read with editing,
+// history and tab completion of the names in scope.
+func Start(in io.Reader, out io.Writer, opts ...evaluator.Option) {
+	env := object.NewModule("__main__", "<stdin>", false).Env
+
+	var r lineReader
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		r = newTerminalReader(f, out, env)
+	} else {
+		r = &plainReader{scanner: bufio.NewScanner(in), out: out}
+	}
+	defer r.Close()
+
+	loop(r, out, env, opts...)
+}
+
+// loop reads and runs statements in env. Ctrl-C discards the statement being
+// typed.
+func loop(r lineReader, out io.Writer, env *object.Environment, opts ...evaluator.Option) {
+	interp := evaluator.New(append([]evaluator.Option{evaluator.WithStdout(out)}, opts...)...)
+
+	source := ""
+	for {
+		prompt := PROMPT
+		if source != "" {
+			prompt = CONTINUATION_PROMPT
+		}
+		line, err := r.ReadLine(prompt)
+		if err == errInterrupted {
+			io.WriteString(out, "\nKeyboardInterrupt\n")
+			source = ""
+			continue
+		}
+		if err != nil {
+			io.WriteString(out, "\n")
+			return
+		}
+
+		source += line + "\n"
+		node, err := parser.ParseInteractive(source, parser.WithLexerOptions(interp.LexerOptions()...))
+		if errors.Is(err, parser.ErrIncomplete) {
+			continue
+		}
+		source = ""
+		if err != nil {
+			printError(out, err)
+			continue
+		}
+
+		if err := run(interp, node, env, out); err != nil {
+			printError(out, err)
+		}
+	}
+}
+
+// run evaluates a statement, echoing the values of expression statements.
+func run(interp *evaluator.Interpreter, node ast.Node, env *object.Environment, out io.Writer) error {
+	block, ok := node.(*ast.BlockNode)
+	if !ok {
+		_, err := interp.Eval(node, env)
+		return err
+	}
+
+	for _, stmt := range block.Statements {
+		res, err := interp.Eval(stmt, env)
+		if err != nil {
+			return err
+		}
+		if _, ok := stmt.(*ast.ExpressionsNode); !ok || res == object.NONE {
+			continue
+		}
+
+		s, err := interp.Repr(res)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, s)
+		env.Set("_", res)
+	}
+	return nil
+}
+
+func printError(out io.Writer, err error) {
+	switch err := err.(type) {
+	case *lexer.Error:
+		fmt.Fprintf(out, "%s: %s\n", err.Kind, err)
+	case *parser.ParseError:
+		fmt.Fprintf(out, "SyntaxError: %s\n", err)
+	default:
+		fmt.Fprintln(out, err)
+	}
+}
+
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *plainReader) Close() error { return nil }
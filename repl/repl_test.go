@@ -0,0 +1,93 @@
+package repl
+
+import (
+	"io"
+	"snek/evaluator"
+	"snek/object"
+	"strings"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 1\n", ">>> 2\n>>> \n"},
+		{"None\nx = 3\nx\n", ">>> >>> >>> 3\n>>> \n"},
+		{"'a'\n_ + 'b'\n", ">>> 'a'\n>>> 'ab'\n>>> \n"},
+		{"print('hi')\n", ">>> hi\n>>> \n"},
+		{"1; 2\n", ">>> 1\n2\n>>> \n"},
+		{"if True:\n    print('yes')\n\n", ">>> ... ... yes\n>>> \n"},
+		{"f(1,\n2)\n", ">>> ... NameError: name 'f' is not defined\n>>> \n"},
+		{"def f():\n    return 5\n\nf()\n", ">>> ... ... >>> 5\n>>> \n"},
+		{"x = )\nx = 4\nx\n", ">>> SyntaxError: line 1, column 5: unmatched ')'\n>>> >>> 4\n>>> \n"},
+		{"1 / 0\n2\n", ">>> ZeroDivisionError: division by zero\n>>> 2\n>>> \n"},
+		{"if True:\n\tx = 1\n        y = 2\n\n", ">>> ... ... TabError: line 3, column 1: inconsistent use of tabs and spaces in indentation\n>>> >>> \n"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		Start(strings.NewReader(tt.input), &out)
+		if out.String() != tt.expected {
+			t.Errorf("Start with %q printed %q, want %q", tt.input, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestStartOptions(t *testing.T) {
+	var out strings.Builder
+	Start(strings.NewReader("assert False\n1\n"), &out, evaluator.WithOptimize())
+	if expected := ">>> >>> 1\n>>> \n"; out.String() != expected {
+		t.Errorf("Start with -O printed %q, want %q", out.String(), expected)
+	}
+}
+
+// scriptedReader returns lines, or errors, in turn, then io.EOF.
+type scriptedReader struct {
+	lines []any
+	out   io.Writer
+}
+
+func (r *scriptedReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+	if len(r.lines) == 0 {
+		return "", io.EOF
+	}
+	next := r.lines[0]
+	r.lines = r.lines[1:]
+	if err, ok := next.(error); ok {
+		return "", err
+	}
+	return next.(string), nil
+}
+
+func (r *scriptedReader) Close() error { return nil }
+
+func TestInterrupt(t *testing.T) {
+	var out strings.Builder
+	r := &scriptedReader{lines: []any{"if True:", errInterrupted, "1", errInterrupted, "2"}, out: &out}
+	loop(r, &out, object.NewModule("__main__", "<stdin>", false).Env)
+
+	expected := ">>> ... \nKeyboardInterrupt\n>>> 1\n>>> \nKeyboardInterrupt\n>>> 2\n>>> \n"
+	if out.String() != expected {
+		t.Errorf("printed %q, want %q", out.String(), expected)
+	}
+}
+
+func TestTerminalInterrupt(t *testing.T) {
+	in, w := io.Pipe()
+	r := &terminalReader{in: interruptReader{in}, out: io.Discard, env: object.NewEnvironment()}
+	r.reset()
+
+	go func() {
+		w.Write([]byte("abc"))
+		w.Write([]byte{CTRL_C})
+		w.Write([]byte("x = 1\r"))
+	}()
+	if _, err := r.readLine(PROMPT); err != errInterrupted {
+		t.Fatalf("reading Ctrl-C gave %v, want errInterrupted", err)
+	}
+	if line, err := r.readLine(PROMPT); err != nil || line != "x = 1" {
+		t.Errorf("reading after Ctrl-C gave %q, %v, want \"x = 1\"", line, err)
+	}
+}
@@ -0,0 +1,218 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"snek/ast"
+	"snek/evaluator"
+	"snek/object"
+	"snek/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	HISTORY_FILE  = ".snek_history"
+	HISTORY_LIMIT = 1000
+	CTRL_C        = 3
+)
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// terminalReader edits lines with the terminal in raw mode, restoring it
+// between lines so that programs print normally.
+type terminalReader struct {
+	fd      int
+	in      io.Reader
+	out     io.Writer
+	term    *term.Terminal
+	env     *object.Environment
+	history *fileHistory
+}
+
+func newTerminalReader(f *os.File, out io.Writer, env *object.Environment) *terminalReader {
+	r := &terminalReader{fd: int(f.Fd()), in: interruptReader{f}, out: out, env: env}
+	if h, err := openHistory(); err == nil {
+		r.history = h
+	}
+	r.reset()
+	return r
+}
+
+// reset starts over with a new terminal, dropping the line being edited.
+func (r *terminalReader) reset() {
+	r.term = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{r.in, r.out}, "")
+	r.term.AutoCompleteCallback = r.complete
+	if r.history != nil {
+		r.term.History = r.history
+	}
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+	return r.readLine(prompt)
+}
+
+func (r *terminalReader) readLine(prompt string) (string, error) {
+	r.term.SetPrompt(prompt)
+	line, err := r.term.ReadLine()
+	switch err {
+	case term.ErrPasteIndicator:
+		err = nil
+	case errInterrupted:
+		r.reset()
+	}
+	return line, err
+}
+
+// interruptReader reports Ctrl-C as errInterrupted. term.Terminal would
+// return io.EOF for it, the same as for Ctrl-D, and keep the key to read
+// again.
+type interruptReader struct {
+	io.Reader
+}
+
+func (r interruptReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if bytes.IndexByte(p[:n], CTRL_C) >= 0 {
+		return 0, errInterrupted
+	}
+	return n, err
+}
+
+func (r *terminalReader) Close() error {
+	if r.history == nil {
+		return nil
+	}
+	return r.history.file.Close()
+}
+
+// complete handles tab. At the start of a line it indents; otherwise it
+// completes the name before the cursor from the names in scope, builtins and
+// keywords, listing the choices when they share no longer prefix.
+func (r *terminalReader) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	if strings.TrimSpace(line[:pos]) == "" {
+		return line[:pos] + ast.INDENT + line[pos:], pos + len(ast.INDENT), true
+	}
+
+	start := pos
+	for start > 0 {
+		c, size := utf8.DecodeLastRuneInString(line[:start])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			break
+		}
+		start -= size
+	}
+	prefix := line[start:pos]
+	if prefix == "" || strings.HasSuffix(line[:start], ".") {
+		return "", 0, false
+	}
+
+	matches := r.names(prefix)
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if common == prefix {
+		fmt.Fprintln(r.term, strings.Join(matches, "  "))
+		return "", 0, false
+	}
+	return line[:start] + common + line[pos:], start + len(common), true
+}
+
+// names returns the names starting with prefix that are defined, builtin or
+// keywords, sorted.
+func (r *terminalReader) names(prefix string) []string {
+	var names []string
+	for _, list := range [][]string{r.env.Names(), evaluator.BuiltinNames(), token.Keywords()} {
+		for _, name := range list {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// fileHistory is the line history, kept in a file in the home directory so
+// that it outlives the session.
+type fileHistory struct {
+	lines []string // Oldest first
+	file  *os.File
+}
+
+func openHistory() (*fileHistory, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(home, HISTORY_FILE)
+
+	h := &fileHistory{}
+	data, _ := os.ReadFile(path)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			h.lines = append(h.lines, line)
+		}
+	}
+
+	// Rewrite the file if it has grown past the limit.
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if len(h.lines) > HISTORY_LIMIT {
+		h.lines = h.lines[len(h.lines)-HISTORY_LIMIT:]
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	h.file, err = os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if flags&os.O_TRUNC != 0 {
+		io.WriteString(h.file, strings.Join(h.lines, "\n")+"\n")
+	}
+	return h, nil
+}
+
+func (h *fileHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || len(h.lines) > 0 && h.lines[len(h.lines)-1] == entry {
+		return
+	}
+	h.lines = append(h.lines, entry)
+	if len(h.lines) > HISTORY_LIMIT {
+		h.lines = h.lines[1:]
+	}
+	io.WriteString(h.file, entry+"\n")
+}
+
+func (h *fileHistory) Len() int { return len(h.lines) }
+
+func (h *fileHistory) At(idx int) string {
+	return h.lines[len(h.lines)-1-idx]
+}
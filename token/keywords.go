@@ -0,0 +1,66 @@
+package token
+
+import "slices"
+
+var keywords = map[string]TokenType{
+	"def":      DEF,
+	"if":       IF,
+	"else":     ELSE,
+	"elif":     ELIF,
+	"for":      FOR,
+	"in":       IN,
+	"while":    WHILE,
+	"or":       OR,
+	"and":      AND,
+	"not":      NOT,
+	"pass":     PASS,
+	"return":   RETURN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"global":   GLOBAL,
+	"import":   IMPORT,
+	"from":     FROM,
+	"as":       AS,
+	"yield":    YIELD,
+	"lambda":   LAMBDA,
+	"class":    CLASS,
+	"with":     WITH,
+	"assert":   ASSERT,
+	"del":      DEL,
+	"raise":    RAISE,
+	"try":      TRY,
+	"except":   EXCEPT,
+	"finally":  FINALLY,
+}
+
+// Soft keywords are only keywords in certain positions and otherwise lex as
+// identifiers.
+var softKeywords = []string{"_", "case", "match"}
+
+// LookupIdent returns the token type of a keyword, or IDENTIFIER for any
+// other name.
+func LookupIdent(ident string) TokenType {
+	if t, ok := keywords[ident]; ok {
+		return t
+	}
+	return IDENTIFIER
+}
+
+func IsKeyword(ident string) bool {
+	_, ok := keywords[ident]
+	return ok
+}
+
+func IsSoftKeyword(ident string) bool {
+	return slices.Contains(softKeywords, ident)
+}
+
+// Keywords returns the reserved words, sorted.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	slices.Sort(words)
+	return words
+}
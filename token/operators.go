@@ -0,0 +1,133 @@
+package token
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Binding powers of the expression parser, from loosest to tightest.
+const (
+	PREC_LOWEST int = iota
+	PREC_CONDITIONAL
+	PREC_OR
+	PREC_AND
+	PREC_NOT
+	PREC_COMPARE
+	PREC_SUM
+	PREC_PRODUCT
+	PREC_PREFIX
+	PREC_EXP
+	PREC_ATTR
+)
+
+var precedences = map[TokenType]int{
+	IF:       PREC_CONDITIONAL,
+	OR:       PREC_OR,
+	AND:      PREC_AND,
+	EQ:       PREC_COMPARE,
+	NE:       PREC_COMPARE,
+	LT:       PREC_COMPARE,
+	LE:       PREC_COMPARE,
+	GT:       PREC_COMPARE,
+	GE:       PREC_COMPARE,
+	IN:       PREC_COMPARE,
+	NOT_IN:   PREC_COMPARE,
+	PLUS:     PREC_SUM,
+	MINUS:    PREC_SUM,
+	STAR:     PREC_PRODUCT,
+	SLASH:    PREC_PRODUCT,
+	DSLASH:   PREC_PRODUCT,
+	PERCENT:  PREC_PRODUCT,
+	DSTAR:    PREC_EXP,
+	LPAREN:   PREC_ATTR,
+	LBRACKET: PREC_ATTR,
+	DOT:      PREC_ATTR,
+}
+
+// Precedence returns how tightly a token binds as an infix operator, or
+// PREC_LOWEST if it is not one.
+func Precedence(t TokenType) int {
+	return precedences[t]
+}
+
+// Operator describes an operator or delimiter spelled with punctuation.
+type Operator struct {
+	Literal    string
+	Type       TokenType
+	Precedence int  // As an infix operator; PREC_LOWEST if it is not one
+	RightAssoc bool // Set for '**'
+}
+
+var operators = []Operator{}
+
+var augmented = map[TokenType]TokenType{
+	PLUS_ASSIGN:    PLUS,
+	MINUS_ASSIGN:   MINUS,
+	STAR_ASSIGN:    STAR,
+	SLASH_ASSIGN:   SLASH,
+	DSLASH_ASSIGN:  DSLASH,
+	PERCENT_ASSIGN: PERCENT,
+	DSTAR_ASSIGN:   DSTAR,
+}
+
+var symbols = map[TokenType]string{NOT_IN: "not in"}
+
+func init() {
+	for _, op := range []struct {
+		literal string
+		t       TokenType
+	}{
+		{"==", EQ}, {"!=", NE}, {"<", LT}, {"<=", LE}, {">", GT}, {">=", GE},
+		{"+", PLUS}, {"-", MINUS}, {"*", STAR}, {"/", SLASH}, {"//", DSLASH}, {"%", PERCENT}, {"**", DSTAR},
+		{"=", ASSIGN}, {"+=", PLUS_ASSIGN}, {"-=", MINUS_ASSIGN}, {"*=", STAR_ASSIGN}, {"/=", SLASH_ASSIGN},
+		{"//=", DSLASH_ASSIGN}, {"%=", PERCENT_ASSIGN}, {"**=", DSTAR_ASSIGN},
+		{"(", LPAREN}, {")", RPAREN}, {"[", LBRACKET}, {"]", RBRACKET}, {"{", LBRACE}, {"}", RBRACE},
+		{",", COMMA}, {":=", WALRUS}, {":", COLON}, {";", SEMICOLON}, {".", DOT}, {"@", AT}, {"|", VBAR},
+	} {
+		operators = append(operators, Operator{
+			Literal:    op.literal,
+			Type:       op.t,
+			Precedence: Precedence(op.t),
+			RightAssoc: op.t == DSTAR,
+		})
+		symbols[op.t] = op.literal
+	}
+	for word, t := range keywords {
+		symbols[t] = word
+	}
+}
+
+// Operators returns the operator table, longest literals first so that a
+// lexer trying them in order finds the longest match.
+func Operators() []Operator {
+	ops := slices.Clone(operators)
+	slices.SortStableFunc(ops, func(a, b Operator) int {
+		return cmp.Compare(len(b.Literal), len(a.Literal))
+	})
+	return ops
+}
+
+func LookupOperator(literal string) (Operator, bool) {
+	for _, op := range operators {
+		if op.Literal == literal {
+			return op, true
+		}
+	}
+	return Operator{}, false
+}
+
+// AugmentedOp returns the binary operator applied by an augmented
+// assignment such as '+='.
+func AugmentedOp(t TokenType) (TokenType, bool) {
+	op, ok := augmented[t]
+	return op, ok
+}
+
+// Symbol returns how an operator or keyword is spelled in source, or the
+// type's name for any other token.
+func (t TokenType) Symbol() string {
+	if s, ok := symbols[t]; ok {
+		return s
+	}
+	return t.String()
+}
@@ -1,5 +1,7 @@
 package token
 
+//go:generate stringer -type=TokenType token.go
+
 type TokenType int
 
 const (
@@ -17,10 +19,18 @@ const (
 	RBRACE
 	COMMA
 	COLON
+	WALRUS
 	SEMICOLON
 	DOT
 	NEW_LINE
 	ASSIGN
+	PLUS_ASSIGN
+	MINUS_ASSIGN
+	STAR_ASSIGN
+	SLASH_ASSIGN
+	DSLASH_ASSIGN
+	PERCENT_ASSIGN
+	DSTAR_ASSIGN
 	IF
 	ELSE
 	ELIF
@@ -30,10 +40,20 @@ const (
 	OR
 	AND
 	NOT
-	COMPARE
-	SUM
-	PRODUCT
-	EXP
+	EQ
+	NE
+	LT
+	LE
+	GT
+	GE
+	NOT_IN // Never lexed; the parser joins 'not' and 'in'
+	PLUS
+	MINUS
+	STAR
+	SLASH
+	DSLASH
+	PERCENT
+	DSTAR
 	PASS
 	RETURN
 	BREAK
@@ -41,6 +61,19 @@ const (
 	GLOBAL
 	IMPORT
 	FROM
+	AS
+	YIELD
+	LAMBDA
+	CLASS
+	AT
+	WITH
+	VBAR
+	ASSERT
+	DEL
+	RAISE
+	TRY
+	EXCEPT
+	FINALLY
 	INDENT
 	DEDENT
 	EOF
@@ -50,93 +83,13 @@ type Token struct {
 	Type    TokenType
 	Literal string
 	Pos     int
-}
 
-func (t TokenType) String() string {
-	switch t {
-	case NUMBER:
-		return "NUMBER"
-	case DEF:
-		return "DEF"
-	case IF:
-		return "IF"
-	case ELSE:
-		return "ELSE"
-	case ELIF:
-		return "ELIF"
-	case FOR:
-		return "FOR"
-	case IN:
-		return "IN"
-	case WHILE:
-		return "WHILE"
-	case OR:
-		return "OR"
-	case AND:
-		return "AND"
-	case NOT:
-		return "NOT"
-	case PASS:
-		return "PASS"
-	case BREAK:
-		return "BREAK"
-	case CONTINUE:
-		return "CONTINUE"
-	case RETURN:
-		return "RETURN"
-	case GLOBAL:
-		return "GLOBAL"
-	case IMPORT:
-		return "IMPORT"
-	case FROM:
-		return "FROM"
-	case COMPARE:
-		return "COMPARE"
-	case ASSIGN:
-		return "ASSIGN"
-	case SUM:
-		return "SUM"
-	case EXP:
-		return "EXP"
-	case PRODUCT:
-		return "PRODUCT"
-	case IDENTIFIER:
-		return "IDENTIFIER"
-	case STRING:
-		return "STRING"
-	case NEW_LINE:
-		return "NEW_LINE"
-	case IGNORE:
-		return "IGNORE"
-	case LPAREN:
-		return "LPAREN"
-	case RPAREN:
-		return "RPAREN"
-	case LBRACKET:
-		return "LBRACKET"
-	case RBRACKET:
-		return "RBRACKET"
-	case LBRACE:
-		return "LBRACE"
-	case RBRACE:
-		return "RBRACE"
-	case COMMA:
-		return "COMMA"
-	case COLON:
-		return "COLON"
-	case SEMICOLON:
-		return "SEMICOLON"
-	case DOT:
-		return "DOT"
-	case UNKNOWN:
-		return "UNKNOWN"
-	case INDENT:
-		return "INDENT"
-	case DEDENT:
-		return "DEDENT"
-	case EOF:
-		return "EOF"
-	default:
-		return "UNKNOWN_TYPE"
-	}
+	// Set only when the lexer keeps trivia. Raw is the source text of the
+	// token, which differs from Literal for normalized identifiers. Trailing
+	// holds the whitespace and comment up to the end of the token's line;
+	// any other whitespace, comments, blank lines and line continuations
+	// before the token are Leading.
+	Raw      string
+	Leading  string
+	Trailing string
 }
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookupIdent(t *testing.T) {
+	tests := []struct {
+		ident    string
+		expected TokenType
+	}{
+		{"def", DEF},
+		{"not", NOT},
+		{"finally", FINALLY},
+		{"match", IDENTIFIER},
+		{"case", IDENTIFIER},
+		{"_", IDENTIFIER},
+		{"Def", IDENTIFIER},
+		{"print", IDENTIFIER},
+	}
+	for _, tt := range tests {
+		if got := LookupIdent(tt.ident); got != tt.expected {
+			t.Errorf("LookupIdent(%q) = %s, want %s", tt.ident, got, tt.expected)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	words := Keywords()
+	if !slices.IsSorted(words) {
+		t.Errorf("Keywords() = %v, want them sorted", words)
+	}
+	for _, word := range words {
+		if !IsKeyword(word) || LookupIdent(word) == IDENTIFIER {
+			t.Errorf("Keywords() returned %q, which is not a keyword", word)
+		}
+		if IsSoftKeyword(word) {
+			t.Errorf("%q is both a keyword and a soft keyword", word)
+		}
+	}
+	if len(words) != len(keywords) {
+		t.Errorf("Keywords() returned %d words, want %d", len(words), len(keywords))
+	}
+
+	for _, word := range []string{"match", "case", "_"} {
+		if !IsSoftKeyword(word) || IsKeyword(word) {
+			t.Errorf("%q should be a soft keyword only", word)
+		}
+	}
+	if IsSoftKeyword("type") {
+		t.Errorf("IsSoftKeyword(%q) = true, want false", "type")
+	}
+}
+
+func TestOperators(t *testing.T) {
+	ops := Operators()
+	for i := 1; i < len(ops); i++ {
+		if len(ops[i].Literal) > len(ops[i-1].Literal) {
+			t.Errorf("Operators() lists %q before %q", ops[i-1].Literal, ops[i].Literal)
+		}
+	}
+
+	for _, tt := range []struct {
+		literal    string
+		t          TokenType
+		precedence int
+	}{
+		{"**", DSTAR, PREC_EXP},
+		{"//=", DSLASH_ASSIGN, PREC_LOWEST},
+		{"<=", LE, PREC_COMPARE},
+		{":=", WALRUS, PREC_LOWEST},
+		{"+", PLUS, PREC_SUM},
+	} {
+		op, ok := LookupOperator(tt.literal)
+		if !ok || op.Type != tt.t || op.Precedence != tt.precedence {
+			t.Errorf("LookupOperator(%q) = %+v, %v, want type %s and precedence %d", tt.literal, op, ok, tt.t, tt.precedence)
+		}
+		if op.RightAssoc != (tt.t == DSTAR) {
+			t.Errorf("LookupOperator(%q).RightAssoc = %v", tt.literal, op.RightAssoc)
+		}
+	}
+	if _, ok := LookupOperator("->"); ok {
+		t.Errorf("LookupOperator(%q) found an operator", "->")
+	}
+
+	if p := Precedence(NOT); p != PREC_LOWEST {
+		t.Errorf("Precedence(NOT) = %d, want PREC_LOWEST; 'not in' is the parser's to recognize", p)
+	}
+	if NOT_IN.Symbol() != "not in" || STAR.Symbol() != "*" || DEF.Symbol() != "def" {
+		t.Errorf("got symbols %q, %q and %q", NOT_IN.Symbol(), STAR.Symbol(), DEF.Symbol())
+	}
+}
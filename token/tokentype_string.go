@@ -0,0 +1,95 @@
+// Code generated by "stringer -type=TokenType token.go"; DO NOT EDIT.
+
+package token
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[UNKNOWN-0]
+	_ = x[IGNORE-1]
+	_ = x[NUMBER-2]
+	_ = x[IDENTIFIER-3]
+	_ = x[STRING-4]
+	_ = x[DEF-5]
+	_ = x[LPAREN-6]
+	_ = x[RPAREN-7]
+	_ = x[LBRACKET-8]
+	_ = x[RBRACKET-9]
+	_ = x[LBRACE-10]
+	_ = x[RBRACE-11]
+	_ = x[COMMA-12]
+	_ = x[COLON-13]
+	_ = x[WALRUS-14]
+	_ = x[SEMICOLON-15]
+	_ = x[DOT-16]
+	_ = x[NEW_LINE-17]
+	_ = x[ASSIGN-18]
+	_ = x[PLUS_ASSIGN-19]
+	_ = x[MINUS_ASSIGN-20]
+	_ = x[STAR_ASSIGN-21]
+	_ = x[SLASH_ASSIGN-22]
+	_ = x[DSLASH_ASSIGN-23]
+	_ = x[PERCENT_ASSIGN-24]
+	_ = x[DSTAR_ASSIGN-25]
+	_ = x[IF-26]
+	_ = x[ELSE-27]
+	_ = x[ELIF-28]
+	_ = x[FOR-29]
+	_ = x[IN-30]
+	_ = x[WHILE-31]
+	_ = x[OR-32]
+	_ = x[AND-33]
+	_ = x[NOT-34]
+	_ = x[EQ-35]
+	_ = x[NE-36]
+	_ = x[LT-37]
+	_ = x[LE-38]
+	_ = x[GT-39]
+	_ = x[GE-40]
+	_ = x[NOT_IN-41]
+	_ = x[PLUS-42]
+	_ = x[MINUS-43]
+	_ = x[STAR-44]
+	_ = x[SLASH-45]
+	_ = x[DSLASH-46]
+	_ = x[PERCENT-47]
+	_ = x[DSTAR-48]
+	_ = x[PASS-49]
+	_ = x[RETURN-50]
+	_ = x[BREAK-51]
+	_ = x[CONTINUE-52]
+	_ = x[GLOBAL-53]
+	_ = x[IMPORT-54]
+	_ = x[FROM-55]
+	_ = x[AS-56]
+	_ = x[YIELD-57]
+	_ = x[LAMBDA-58]
+	_ = x[CLASS-59]
+	_ = x[AT-60]
+	_ = x[WITH-61]
+	_ = x[VBAR-62]
+	_ = x[ASSERT-63]
+	_ = x[DEL-64]
+	_ = x[RAISE-65]
+	_ = x[TRY-66]
+	_ = x[EXCEPT-67]
+	_ = x[FINALLY-68]
+	_ = x[INDENT-69]
+	_ = x[DEDENT-70]
+	_ = x[EOF-71]
+}
+
+const _TokenType_name = "UNKNOWNIGNORENUMBERIDENTIFIERSTRINGDEFLPARENRPARENLBRACKETRBRACKETLBRACERBRACECOMMACOLONWALRUSSEMICOLONDOTNEW_LINEASSIGNPLUS_ASSIGNMINUS_ASSIGNSTAR_ASSIGNSLASH_ASSIGNDSLASH_ASSIGNPERCENT_ASSIGNDSTAR_ASSIGNIFELSEELIFFORINWHILEORANDNOTEQNELTLEGTGENOT_INPLUSMINUSSTARSLASHDSLASHPERCENTDSTARPASSRETURNBREAKCONTINUEGLOBALIMPORTFROMASYIELDLAMBDACLASSATWITHVBARASSERTDELRAISETRYEXCEPTFINALLYINDENTDEDENTEOF"
+
+var _TokenType_index = [...]uint16{0, 7, 13, 19, 29, 35, 38, 44, 50, 58, 66, 72, 78, 83, 88, 94, 103, 106, 114, 120, 131, 143, 154, 166, 179, 193, 205, 207, 211, 215, 218, 220, 225, 227, 230, 233, 235, 237, 239, 241, 243, 245, 251, 255, 260, 264, 269, 275, 282, 287, 291, 297, 302, 310, 316, 322, 326, 328, 333, 339, 344, 346, 350, 354, 360, 363, 368, 371, 377, 384, 390, 396, 399}
+
+func (i TokenType) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_TokenType_index)-1 {
+		return "TokenType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _TokenType_name[_TokenType_index[idx]:_TokenType_index[idx+1]]
+}